@@ -24,7 +24,11 @@ import (
 	"github.com/strongdm/comply/internal/config"
 	"github.com/strongdm/comply/internal/gitlab"
 	"github.com/strongdm/comply/internal/jira"
+	"github.com/strongdm/comply/internal/model"
+	"github.com/strongdm/comply/internal/plugin/azuredevops"
 	"github.com/strongdm/comply/internal/plugin/github"
+	"github.com/strongdm/comply/internal/plugin/linear"
+	"github.com/strongdm/comply/internal/plugin/local"
 	"github.com/urfave/cli"
 )
 
@@ -46,16 +50,22 @@ func newApp() *cli.App {
 		app.HideVersion = true
 	}
 	app.Version = Version
+	model.SetVersion(Version)
 	app.Usage = "policy compliance toolkit"
 
 	app.Commands = []cli.Command{
 		beforeCommand(initCommand, notifyVersion),
 	}
 
+	app.Commands = append(app.Commands, beforeCommand(auditCommand, projectMustExist, notifyVersion))
 	app.Commands = append(app.Commands, beforeCommand(buildCommand, projectMustExist, notifyVersion))
+	app.Commands = append(app.Commands, beforeCommand(evidenceCommand, projectMustExist, notifyVersion))
+	app.Commands = append(app.Commands, beforeCommand(lintCommand, projectMustExist, notifyVersion))
 	app.Commands = append(app.Commands, beforeCommand(procedureCommand, projectMustExist, notifyVersion))
+	app.Commands = append(app.Commands, beforeCommand(scheduleCommand, projectMustExist, notifyVersion))
 	app.Commands = append(app.Commands, beforeCommand(schedulerCommand, projectMustExist, notifyVersion))
 	app.Commands = append(app.Commands, beforeCommand(serveCommand, projectMustExist, notifyVersion))
+	app.Commands = append(app.Commands, beforeCommand(soaCommand, projectMustExist, notifyVersion))
 	app.Commands = append(app.Commands, beforeCommand(syncCommand, projectMustExist, notifyVersion))
 	app.Commands = append(app.Commands, beforeCommand(todoCommand, projectMustExist, notifyVersion))
 
@@ -63,6 +73,9 @@ func newApp() *cli.App {
 	github.Register()
 	jira.Register()
 	gitlab.Register()
+	azuredevops.Register()
+	linear.Register()
+	local.Register()
 
 	return app
 }
@@ -101,9 +114,12 @@ func projectMustExist(c *cli.Context) error {
 
 func ticketingMustBeConfigured(c *cli.Context) error {
 	p := config.Config()
-	if p.Tickets == nil || len(p.Tickets) != 1 {
+	if len(p.Tickets) == 0 {
 		return feedbackError("comply.yml must contain a valid ticketing configuration")
 	}
+	if _, err := p.TicketSystem(); err != nil {
+		return feedbackError("comply.yml must contain a valid ticketing configuration: " + err.Error())
+	}
 	return nil
 }
 
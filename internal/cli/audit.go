@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/strongdm/comply/internal/model"
+	"github.com/urfave/cli"
+)
+
+var auditCommand = cli.Command{
+	Name:      "audit",
+	Usage:     "list audits, or create evidence-request tickets for an audit by ID",
+	ArgsUsage: "[auditID]",
+	Action:    auditAction,
+	Before:    projectMustExist,
+}
+
+func auditAction(c *cli.Context) error {
+	d, err := model.ReadData()
+	if err != nil {
+		return err
+	}
+
+	if c.NArg() == 0 {
+		return listAudits(d)
+	}
+
+	if err := ticketingMustBeConfigured(c); err != nil {
+		return err
+	}
+
+	auditID := c.Args().First()
+	for _, a := range d.Audits {
+		if a.ID == auditID {
+			return requestEvidence(d, a)
+		}
+	}
+	return cli.NewExitError(fmt.Sprintf("unknown audit ID: %s", auditID), 1)
+}
+
+func listAudits(d *model.Data) error {
+	w := tablewriter.NewWriter(os.Stdout)
+	w.SetHeader([]string{"ID", "Name", "Auditor", "Period", "Requests", "Open", "Closed"})
+	w.SetAutoWrapText(false)
+
+	for _, a := range d.Audits {
+		var open, closed int
+		for _, t := range d.Tickets {
+			if !t.Bool(model.LabelAudit) || t.AuditID() != a.ID {
+				continue
+			}
+			if t.State == model.Closed {
+				closed++
+			} else {
+				open++
+			}
+		}
+		w.Append([]string{a.ID, a.Name, a.Auditor, a.Period.String(), fmt.Sprintf("%d", len(a.Requests)), fmt.Sprintf("%d", open), fmt.Sprintf("%d", closed)})
+	}
+
+	w.Render()
+	return nil
+}
+
+// requestEvidence creates a ticket for each request without one in the ticket
+// cache; run `comply sync` first to avoid duplicates.
+func requestEvidence(d *model.Data, a *model.Audit) error {
+	requested := make(map[string]bool)
+	for _, t := range d.Tickets {
+		if t.AuditID() == a.ID {
+			requested[t.RequestID()] = true
+		}
+	}
+
+	ts, err := model.DefaultTicketSystem()
+	if err != nil {
+		return cli.NewExitError("error in ticket system configuration", 1)
+	}
+	tp := model.GetPlugin(ts)
+
+	for _, r := range a.Requests {
+		if requested[r.ID] {
+			continue
+		}
+		fmt.Printf("requesting %s (%s)\n", r.Name, r.ID)
+		err = tp.Create(r.Ticket(a), []string{model.LabelComply, model.LabelAudit})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/robfig/cron"
+	"github.com/strongdm/comply/internal/model"
+	"github.com/strongdm/comply/internal/ticket"
+)
+
+// maxDaemonErrors bounds the errors reported by the status endpoint.
+const maxDaemonErrors = 20
+
+// daemon runs the scheduler until interrupted: tickets are synced and
+// evidence freshness checked every syncInterval, and each procedure is
+// triggered when its cron schedule comes due.
+type daemon struct {
+	syncInterval time.Duration
+	entries      map[string]*daemonEntry
+	status       *daemonStatus
+
+	// replaced in tests
+	now             func() time.Time
+	syncTickets     func() error
+	readProcedures  func() ([]*model.Procedure, error)
+	trigger         func(p *model.Procedure, now time.Time) (*ticket.Plan, error)
+	triggerEvidence func() error
+}
+
+type daemonEntry struct {
+	procedure *model.Procedure
+	schedule  cron.Schedule
+	loc       *time.Location
+	next      time.Time
+}
+
+// daemonStatus is served as JSON by the status endpoint.
+type daemonStatus struct {
+	mu         sync.Mutex
+	Started    time.Time                   `json:"started"`
+	LastSync   *time.Time                  `json:"lastSync,omitempty"`
+	SyncError  string                      `json:"syncError,omitempty"`
+	Procedures map[string]*procedureStatus `json:"procedures"`
+	Errors     []daemonError               `json:"errors"`
+}
+
+type procedureStatus struct {
+	Cron        string     `json:"cron"`
+	Next        *time.Time `json:"next,omitempty"`
+	LastRun     *time.Time `json:"lastRun,omitempty"`
+	LastTrigger *time.Time `json:"lastTrigger,omitempty"`
+	LastResult  string     `json:"lastResult,omitempty"`
+}
+
+type daemonError struct {
+	Time  time.Time `json:"time"`
+	Error string    `json:"error"`
+}
+
+func newDaemon(syncInterval time.Duration) *daemon {
+	return &daemon{
+		syncInterval: syncInterval,
+		entries:      make(map[string]*daemonEntry),
+		status: &daemonStatus{
+			Started:    time.Now().UTC(),
+			Procedures: make(map[string]*procedureStatus),
+			Errors:     []daemonError{},
+		},
+		now:             func() time.Time { return time.Now().UTC() },
+		syncTickets:     func() error { return syncAll(false) },
+		readProcedures:  model.ReadProcedures,
+		trigger:         ticket.TriggerProcedure,
+		triggerEvidence: ticket.TriggerEvidenceExpiry,
+	}
+}
+
+func runDaemon(syncInterval time.Duration, statusAddr string, statusPort int) error {
+	d := newDaemon(syncInterval)
+
+	var server *http.Server
+	if statusPort > 0 {
+		addr := net.JoinHostPort(statusAddr, strconv.Itoa(statusPort))
+		server = &http.Server{Addr: addr, Handler: d.status}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("status endpoint failed: %s", err)
+			}
+		}()
+		log.Printf("serving scheduler status at http://%s/", addr)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	defer signal.Stop(signals)
+
+	// catch up on occurrences missed while not running
+	now := d.now()
+	d.sync(now)
+	d.recordErr(ticket.TriggerScheduled())
+	nextSync := now.Add(syncInterval)
+
+	for {
+		timer := time.NewTimer(d.wake(nextSync).Sub(d.now()))
+		select {
+		case sig := <-signals:
+			timer.Stop()
+			log.Printf("received %s, stopping scheduler", sig)
+			if server == nil {
+				return nil
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return server.Shutdown(ctx)
+		case <-timer.C:
+		}
+
+		nextSync = d.tick(d.now(), nextSync)
+	}
+}
+
+// wake is when the daemon next has work: the next sync, or the earliest
+// procedure coming due before it.
+func (d *daemon) wake(nextSync time.Time) time.Time {
+	wake := nextSync
+	for _, e := range d.entries {
+		if !e.next.IsZero() && e.next.Before(wake) {
+			wake = e.next
+		}
+	}
+	return wake
+}
+
+// tick syncs when nextSync has passed, then fires every procedure that has
+// come due, returning when to sync next.
+func (d *daemon) tick(now, nextSync time.Time) time.Time {
+	if !now.Before(nextSync) {
+		d.sync(now)
+		nextSync = now.Add(d.syncInterval)
+	}
+	for _, e := range d.entries {
+		if e.next.IsZero() || now.Before(e.next) {
+			continue
+		}
+		d.fire(e, now)
+		e.next = e.schedule.Next(now.In(e.loc))
+		d.status.update(e.procedure.ID, func(ps *procedureStatus) { ps.Next = timePtr(e.next) })
+	}
+	return nextSync
+}
+
+// sync refreshes the ticket cache and procedures, then requests expiring
+// evidence once the cache is current.
+func (d *daemon) sync(now time.Time) {
+	err := d.syncTickets()
+	d.status.mu.Lock()
+	if err == nil {
+		d.status.LastSync = &now
+		d.status.SyncError = ""
+	} else {
+		d.status.SyncError = err.Error()
+	}
+	d.status.mu.Unlock()
+	d.recordErr(d.loadProcedures(now))
+	if err != nil {
+		// requesting evidence from a stale cache could duplicate tickets
+		d.recordErr(err)
+		return
+	}
+	d.recordErr(d.triggerEvidence())
+}
+
+// loadProcedures schedules procedures added or changed since the last load.
+func (d *daemon) loadProcedures(now time.Time) error {
+	procedures, err := d.readProcedures()
+	if err != nil {
+		return err
+	}
+
+	entries := make(map[string]*daemonEntry)
+	var invalid error
+	for _, p := range procedures {
+		if p.Cron == "" {
+			continue
+		}
+		loc, err := p.Location()
+		if err != nil {
+			invalid = fmt.Errorf("invalid timezone for procedure %s: %s", p.ID, err)
+			continue
+		}
+		if e, ok := d.entries[p.ID]; ok && e.procedure.Cron == p.Cron && e.loc.String() == loc.String() {
+			e.procedure = p
+			entries[p.ID] = e
+			continue
+		}
+		schedule, err := cron.Parse(p.Cron)
+		if err != nil {
+			invalid = fmt.Errorf("invalid cron expression for procedure %s: %s", p.ID, err)
+			continue
+		}
+		entries[p.ID] = &daemonEntry{procedure: p, schedule: schedule, loc: loc, next: schedule.Next(now.In(loc))}
+	}
+	d.entries = entries
+
+	d.status.mu.Lock()
+	procedureStatuses := make(map[string]*procedureStatus)
+	for id, e := range entries {
+		ps, ok := d.status.Procedures[id]
+		if !ok {
+			ps = &procedureStatus{}
+		}
+		ps.Cron = e.procedure.Cron
+		ps.Next = timePtr(e.next)
+		procedureStatuses[id] = ps
+	}
+	d.status.Procedures = procedureStatuses
+	d.status.mu.Unlock()
+	return invalid
+}
+
+func (d *daemon) fire(e *daemonEntry, now time.Time) {
+	p, err := d.trigger(e.procedure, now)
+	if err != nil {
+		d.recordErr(err)
+		d.status.update(e.procedure.ID, func(ps *procedureStatus) {
+			ps.LastRun = &now
+			ps.LastResult = err.Error()
+		})
+		return
+	}
+	if len(p.Trigger) == 0 {
+		log.Printf("skipping procedure %s: %s", e.procedure.ID, p.Reason)
+	}
+	d.status.update(e.procedure.ID, func(ps *procedureStatus) {
+		ps.LastRun = &now
+		ps.LastResult = p.Reason
+		if len(p.Trigger) > 0 {
+			ps.LastTrigger = timePtr(p.Trigger[len(p.Trigger)-1])
+		}
+	})
+}
+
+// recordErr logs and keeps the most recent errors, ignoring nil.
+func (d *daemon) recordErr(err error) {
+	if err == nil {
+		return
+	}
+	log.Printf("error: %s", err)
+	d.status.mu.Lock()
+	defer d.status.mu.Unlock()
+	d.status.Errors = append(d.status.Errors, daemonError{Time: d.now(), Error: err.Error()})
+	if len(d.status.Errors) > maxDaemonErrors {
+		d.status.Errors = d.status.Errors[len(d.status.Errors)-maxDaemonErrors:]
+	}
+}
+
+func (s *daemonStatus) update(procedureID string, f func(ps *procedureStatus)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ps, ok := s.Procedures[procedureID]; ok {
+		f(ps)
+	}
+}
+
+// ServeHTTP reports the status as JSON, failing while tickets cannot be synced.
+func (s *daemonStatus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	if s.SyncError != "" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(s)
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
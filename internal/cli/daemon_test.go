@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/strongdm/comply/internal/model"
+	"github.com/strongdm/comply/internal/ticket"
+)
+
+// newTestDaemon schedules the given procedures at now, recording the times
+// each procedure is triggered.
+func newTestDaemon(t *testing.T, now time.Time, procedures ...*model.Procedure) (*daemon, map[string][]time.Time) {
+	triggered := make(map[string][]time.Time)
+	d := newDaemon(time.Hour)
+	d.now = func() time.Time { return now }
+	d.syncTickets = func() error { return nil }
+	d.readProcedures = func() ([]*model.Procedure, error) { return procedures, nil }
+	d.trigger = func(p *model.Procedure, now time.Time) (*ticket.Plan, error) {
+		triggered[p.ID] = append(triggered[p.ID], now)
+		return &ticket.Plan{Procedure: p, Trigger: []time.Time{now}, Reason: "due"}, nil
+	}
+	d.triggerEvidence = func() error { return nil }
+	if err := d.loadProcedures(now); err != nil {
+		t.Fatal(err)
+	}
+	return d, triggered
+}
+
+func TestDaemonFiresWhenDue(t *testing.T) {
+	start := time.Date(2018, 5, 15, 8, 0, 0, 0, time.UTC)
+	d, triggered := newTestDaemon(t, start, &model.Procedure{ID: "patch", Cron: "0 0 9 * * *"})
+
+	nextSync := start.Add(d.syncInterval)
+	due := time.Date(2018, 5, 15, 9, 0, 0, 0, time.UTC)
+	if wake := d.wake(nextSync); !wake.Equal(due) {
+		t.Fatalf("expected to wake when patch comes due at %s, got %s", due, wake)
+	}
+
+	nextSync = d.tick(due.Add(-time.Minute), nextSync)
+	if len(triggered["patch"]) != 0 {
+		t.Fatalf("expected patch not to fire early, got %v", triggered)
+	}
+
+	nextSync = d.tick(due, nextSync)
+	if len(triggered["patch"]) != 1 || !triggered["patch"][0].Equal(due) {
+		t.Fatalf("expected patch to fire at %s, got %v", due, triggered)
+	}
+	if !nextSync.Equal(due.Add(d.syncInterval)) {
+		t.Errorf("expected the next sync an interval after %s, got %s", due, nextSync)
+	}
+	if next := d.wake(due.AddDate(0, 0, 2)); !next.Equal(due.AddDate(0, 0, 1)) {
+		t.Errorf("expected patch rescheduled for the next day, got %s", next)
+	}
+	if ps := d.status.Procedures["patch"]; ps.LastTrigger == nil || !ps.LastTrigger.Equal(due) || ps.LastResult != "due" {
+		t.Errorf("unexpected status: %+v", ps)
+	}
+}
+
+func TestDaemonReschedules(t *testing.T) {
+	start := time.Date(2018, 5, 15, 8, 0, 0, 0, time.UTC)
+	patch := &model.Procedure{ID: "patch", Cron: "0 0 9 * * *"}
+	d, _ := newTestDaemon(t, start, patch)
+	far := start.AddDate(1, 0, 0)
+
+	// unchanged schedules keep their next run
+	d.entries["patch"].next = start.Add(30 * time.Minute)
+	d.readProcedures = func() ([]*model.Procedure, error) {
+		return []*model.Procedure{{ID: "patch", Cron: "0 0 9 * * *"}}, nil
+	}
+	if err := d.loadProcedures(start); err != nil {
+		t.Fatal(err)
+	}
+	if wake := d.wake(far); !wake.Equal(start.Add(30 * time.Minute)) {
+		t.Errorf("expected an unchanged schedule to keep its next run, got %s", wake)
+	}
+
+	d.readProcedures = func() ([]*model.Procedure, error) {
+		return []*model.Procedure{{ID: "patch", Cron: "0 0 10 * * *"}}, nil
+	}
+	if err := d.loadProcedures(start); err != nil {
+		t.Fatal(err)
+	}
+	if wake, expected := d.wake(far), time.Date(2018, 5, 15, 10, 0, 0, 0, time.UTC); !wake.Equal(expected) {
+		t.Errorf("expected the new cron schedule at %s, got %s", expected, wake)
+	}
+
+	d.readProcedures = func() ([]*model.Procedure, error) {
+		return []*model.Procedure{{ID: "patch", Cron: "0 0 10 * * *", Timezone: "America/New_York"}}, nil
+	}
+	if err := d.loadProcedures(start); err != nil {
+		t.Fatal(err)
+	}
+	// 10am in New York is 2pm UTC during daylight saving time
+	if wake, expected := d.wake(far), time.Date(2018, 5, 15, 14, 0, 0, 0, time.UTC); !wake.Equal(expected) {
+		t.Errorf("expected the new timezone at %s, got %s", expected, wake)
+	}
+
+	d.readProcedures = func() ([]*model.Procedure, error) { return nil, nil }
+	if err := d.loadProcedures(start); err != nil {
+		t.Fatal(err)
+	}
+	if wake := d.wake(far); !wake.Equal(far) || len(d.status.Procedures) != 0 {
+		t.Errorf("expected removed procedures to be unscheduled, got %s and %v", wake, d.status.Procedures)
+	}
+}
+
+func TestDaemonStatusFailsAfterSyncError(t *testing.T) {
+	start := time.Date(2018, 5, 15, 8, 0, 0, 0, time.UTC)
+	d, _ := newTestDaemon(t, start)
+
+	d.sync(start)
+	w := httptest.NewRecorder()
+	d.status.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200 after a successful sync, got %d", w.Code)
+	}
+
+	evidenceChecked := false
+	d.syncTickets = func() error { return errors.New("unable to sync jira") }
+	d.triggerEvidence = func() error {
+		evidenceChecked = true
+		return nil
+	}
+	d.sync(start.Add(time.Hour))
+	w = httptest.NewRecorder()
+	d.status.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), "unable to sync jira") {
+		t.Errorf("expected 503 reporting the sync error, got %d: %s", w.Code, w.Body)
+	}
+	if evidenceChecked {
+		t.Error("expected evidence not to be requested from a stale cache")
+	}
+}
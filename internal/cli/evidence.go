@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/strongdm/comply/internal/model"
+	"github.com/urfave/cli"
+)
+
+var evidenceCommand = cli.Command{
+	Name:  "evidence",
+	Usage: "list controls with and without evidence for the current audit period",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "missing",
+			Usage: "only list controls without fresh evidence",
+		},
+	},
+	Action: evidenceAction,
+	Before: projectMustExist,
+}
+
+func evidenceAction(c *cli.Context) error {
+	d, err := model.ReadData()
+	if err != nil {
+		return err
+	}
+
+	audit := model.CurrentAudit(d.Audits, time.Now())
+	if audit != nil {
+		fmt.Printf("Audit period: %s (%s)\n\n", audit.Period, audit.Name)
+	} else {
+		fmt.Printf("No audit period declared; all evidence is considered fresh\n\n")
+	}
+
+	w := tablewriter.NewWriter(os.Stdout)
+	w.SetHeader([]string{"Standard", "Control", "Status", "Name", "Evidence", "Latest"})
+	w.SetAutoWrapText(false)
+
+	var fresh, total int
+	for _, ce := range model.EvidenceStatus(d, audit) {
+		total++
+		status := color.RedString("MISSING")
+		if ce.Fresh {
+			fresh++
+			status = color.GreenString("FRESH")
+		} else if len(ce.Evidence) > 0 {
+			status = color.YellowString("STALE")
+		}
+		if ce.Fresh && c.Bool("missing") {
+			continue
+		}
+
+		latest := ""
+		if len(ce.Evidence) > 0 {
+			latest = fmt.Sprintf("%s (%s)", ce.Evidence[0].Path, ce.Evidence[0].DateString())
+		}
+		w.Append([]string{ce.Key.Standard, ce.Key.Control, status, ce.Name, fmt.Sprintf("%d", len(ce.Evidence)), latest})
+	}
+
+	w.Render()
+
+	fmt.Printf("\n%d of %d applicable controls have fresh evidence\n", fresh, total)
+	return nil
+}
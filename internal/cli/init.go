@@ -100,7 +100,7 @@ func initAction(c *cli.Context) error {
 
 	chooser = promptui.Select{
 		Label: "Ticket System",
-		Items: []string{"GitHub", "Jira", "GitLab", "None"},
+		Items: []string{"GitHub", "Jira", "GitLab", "Azure DevOps", "Linear", "Local (tickets folder)", "None"},
 	}
 
 	choice, _, err = chooser.Run()
@@ -119,6 +119,12 @@ func initAction(c *cli.Context) error {
 		ticketing = model.Jira
 	case 2:
 		ticketing = model.GitLab
+	case 3:
+		ticketing = model.AzureDevOps
+	case 4:
+		ticketing = model.Linear
+	case 5:
+		ticketing = model.Local
 	default:
 		ticketing = model.NoTickets
 	}
@@ -179,7 +185,7 @@ func initAction(c *cli.Context) error {
 
 	success := fmt.Sprintf("%s Compliance initialized successfully!", name)
 	fmt.Printf("%s %s\n\n", promptui.IconGood, success)
-	fmt.Println(whatNow)
+	fmt.Printf("%s\n", whatNow)
 
 	return nil
 }
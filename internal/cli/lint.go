@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/strongdm/comply/internal/model"
+	"github.com/urfave/cli"
+)
+
+var lintCommand = cli.Command{
+	Name:   "lint",
+	Usage:  "validate narratives, policies, procedures and standards",
+	Action: lintAction,
+	Before: projectMustExist,
+}
+
+func lintAction(c *cli.Context) error {
+	diagnostics, err := model.Lint()
+	if err != nil {
+		return err
+	}
+
+	errorCount, warningCount := 0, 0
+	for _, d := range diagnostics {
+		severity := color.YellowString(string(d.Severity))
+		if d.Severity == model.SeverityError {
+			severity = color.RedString(string(d.Severity))
+			errorCount++
+		} else {
+			warningCount++
+		}
+		fmt.Printf("%s:%d: %s: %s [%s]\n", d.File, d.Line, severity, d.Message, d.Rule)
+	}
+
+	if errorCount > 0 {
+		return cli.NewExitError(fmt.Sprintf("%d error(s), %d warning(s)", errorCount, warningCount), 1)
+	}
+	fmt.Printf("%d error(s), %d warning(s)\n", errorCount, warningCount)
+	return nil
+}
@@ -2,8 +2,8 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
-	"github.com/strongdm/comply/internal/config"
 	"github.com/strongdm/comply/internal/model"
 	"github.com/urfave/cli"
 )
@@ -29,19 +29,14 @@ func procedureAction(c *cli.Context) error {
 
 	procedureID := c.Args().First()
 
-	ts, err := config.Config().TicketSystem()
-	if err != nil {
-		return cli.NewExitError("error in ticket system configuration", 1)
-	}
-
-	tp := model.GetPlugin(model.TicketSystem(ts))
-
 	for _, procedure := range procedures {
 		if procedure.ID == procedureID {
-			err = tp.Create(&model.Ticket{
-				Name: procedure.Name,
-				Body: fmt.Sprintf("%s\n\n\n---\nProcedure-ID: %s", procedure.Body, procedure.ID),
-			}, []string{"comply", "comply-procedure"})
+			ts, err := model.ProcedureTicketSystem(procedure)
+			if err != nil {
+				return cli.NewExitError(err, 1)
+			}
+			tp := model.GetPlugin(ts)
+			err = tp.Create(procedure.Ticket(time.Now()), procedure.TicketLabels())
 			if err != nil {
 				return err
 			}
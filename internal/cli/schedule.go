@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/strongdm/comply/internal/model"
+	"github.com/strongdm/comply/internal/ticket"
+	"github.com/urfave/cli"
+)
+
+// scheduleFormat displays scheduled times to the minute.
+const scheduleFormat = "2006-01-02 15:04 MST"
+
+var scheduleCommand = cli.Command{
+	Name:  "schedule",
+	Usage: "list upcoming occurrences of each procedure's cron schedule",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "count",
+			Value: 5,
+			Usage: "maximum occurrences listed per procedure",
+		},
+		cli.IntFlag{
+			Name:  "days",
+			Value: 365,
+			Usage: "only list occurrences within this many days",
+		},
+		cli.StringFlag{
+			Name:  "format",
+			Value: "table",
+			Usage: "output format: table or json",
+		},
+	},
+	Action: scheduleAction,
+	Before: projectMustExist,
+}
+
+type scheduledProcedure struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Cron        string      `json:"cron"`
+	Timezone    string      `json:"timezone"`
+	Occurrences []time.Time `json:"occurrences"`
+	Error       string      `json:"error,omitempty"`
+}
+
+func scheduleAction(c *cli.Context) error {
+	format := strings.ToLower(c.String("format"))
+	if format != "table" && format != "json" {
+		return cli.NewExitError(fmt.Sprintf("unknown format: %s", c.String("format")), 1)
+	}
+
+	procedures, err := model.ReadProcedures()
+	if err != nil {
+		return err
+	}
+
+	from := time.Now()
+	until := from.AddDate(0, 0, c.Int("days"))
+	scheduled := []*scheduledProcedure{}
+	for _, p := range procedures {
+		if p.Cron == "" {
+			continue
+		}
+		sp := &scheduledProcedure{ID: p.ID, Name: p.Name, Cron: p.Cron, Occurrences: []time.Time{}}
+		scheduled = append(scheduled, sp)
+		loc, err := p.Location()
+		if err != nil {
+			sp.Error = "invalid timezone: " + err.Error()
+			continue
+		}
+		sp.Timezone = loc.String()
+		occurrences, err := ticket.Occurrences(p.Cron, from.In(loc), until, c.Int("count"))
+		if err != nil {
+			sp.Error = "invalid cron expression: " + err.Error()
+		} else {
+			sp.Occurrences = append(sp.Occurrences, occurrences...)
+		}
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(scheduled)
+	}
+
+	w := tablewriter.NewWriter(os.Stdout)
+	w.SetHeader([]string{"Procedure", "Name", "Cron", "Timezone", "Occurrences"})
+	w.SetAutoWrapText(false)
+	for _, sp := range scheduled {
+		var times []string
+		for _, o := range sp.Occurrences {
+			times = append(times, o.Format(scheduleFormat))
+		}
+		if sp.Error != "" {
+			times = []string{sp.Error}
+		}
+		w.Append([]string{sp.ID, sp.Name, sp.Cron, sp.Timezone, strings.Join(times, "\n")})
+	}
+	w.Render()
+	return nil
+}
@@ -1,21 +1,110 @@
 package cli
 
 import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/strongdm/comply/internal/model"
 	"github.com/strongdm/comply/internal/ticket"
 	"github.com/urfave/cli"
 )
 
 var schedulerCommand = cli.Command{
-	Name:   "scheduler",
-	Usage:  "create tickets based on procedure schedule",
+	Name:  "scheduler",
+	Usage: "create tickets based on procedure schedule and evidence freshness",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "list the tickets that would be created, and why, without creating them",
+		},
+		cli.BoolFlag{
+			Name:  "daemon",
+			Usage: "keep running, creating tickets as procedures come due",
+		},
+		cli.DurationFlag{
+			Name:  "sync-interval",
+			Value: 15 * time.Minute,
+			Usage: "in daemon mode, how often to sync tickets and check evidence freshness",
+		},
+		cli.StringFlag{
+			Name:  "status-addr",
+			Value: "127.0.0.1",
+			Usage: "in daemon mode, address serving scheduler status",
+		},
+		cli.IntFlag{
+			Name:  "status-port",
+			Value: 4001,
+			Usage: "in daemon mode, port serving scheduler status as JSON (0 to disable)",
+		},
+	},
 	Action: schedulerAction,
 	Before: beforeAll(projectMustExist, ticketingMustBeConfigured),
 }
 
 func schedulerAction(c *cli.Context) error {
+	if c.Bool("daemon") {
+		if c.Bool("dry-run") {
+			return cli.NewExitError("--daemon and --dry-run cannot be combined", 1)
+		}
+		if c.Duration("sync-interval") <= 0 {
+			return cli.NewExitError("--sync-interval must be positive", 1)
+		}
+		return runDaemon(c.Duration("sync-interval"), c.String("status-addr"), c.Int("status-port"))
+	}
+
 	err := syncAction(c)
 	if err != nil {
 		return err
 	}
-	return ticket.TriggerScheduled()
+	if c.Bool("dry-run") {
+		return schedulerDryRun()
+	}
+	err = ticket.TriggerScheduled()
+	if err != nil {
+		return err
+	}
+	return ticket.TriggerEvidenceExpiry()
+}
+
+func schedulerDryRun() error {
+	now := time.Now().UTC()
+	plans, err := ticket.PlanScheduled(now)
+	if err != nil {
+		return err
+	}
+
+	w := tablewriter.NewWriter(os.Stdout)
+	w.SetHeader([]string{"Procedure", "Cron", "Last Ticket", "Next", "Action", "Reason"})
+	w.SetAutoWrapText(false)
+	for _, p := range plans {
+		last := ""
+		if p.LastTicket != nil {
+			last = fmt.Sprintf("%s (%s)", p.LastTicket.ID, p.LastTicket.CreatedAt.Format(model.DateFormat))
+		}
+		next := ""
+		if !p.Next.IsZero() {
+			next = p.Next.Format(scheduleFormat)
+		}
+		action := "skip"
+		if len(p.Trigger) > 0 {
+			action = color.YellowString("TRIGGER")
+		}
+		w.Append([]string{p.Procedure.ID, p.Procedure.Cron, last, next, action, p.Reason})
+	}
+	w.Render()
+
+	checks, err := ticket.PlanEvidenceExpiry(now)
+	if err != nil {
+		return err
+	}
+	if len(checks) > 0 {
+		fmt.Printf("\nEvidence requests:\n")
+		for _, check := range checks {
+			fmt.Printf("  %s (%s)\n", check.Subject(), check.State)
+		}
+	}
+	return nil
 }
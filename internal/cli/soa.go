@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/strongdm/comply/internal/render"
+	"github.com/urfave/cli"
+)
+
+var soaCommand = cli.Command{
+	Name:  "soa",
+	Usage: "generate a Statement of Applicability listing every control, its applicability and implementing documents",
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "standard",
+			Usage: "limit the statement to a standard (repeatable; default: all standards)",
+		},
+		cli.StringFlag{
+			Name:  "format",
+			Value: "pdf,csv,xlsx",
+			Usage: "comma-separated output formats: pdf, csv, xlsx",
+		},
+	},
+	Action: soaAction,
+	Before: soaPandocMustExist,
+}
+
+func soaFormats(c *cli.Context) []string {
+	var formats []string
+	for _, f := range strings.Split(c.String("format"), ",") {
+		if f = strings.ToLower(strings.TrimSpace(f)); f != "" {
+			formats = append(formats, f)
+		}
+	}
+	return formats
+}
+
+// soaPandocMustExist only requires pandoc when a PDF is requested.
+func soaPandocMustExist(c *cli.Context) error {
+	for _, f := range soaFormats(c) {
+		if f == render.SoAPDF {
+			return beforeAll(pandocMustExist, cleanContainers)(c)
+		}
+	}
+	return nil
+}
+
+func soaAction(c *cli.Context) error {
+	err := render.SoA("output", c.StringSlice("standard"), soaFormats(c))
+	if err != nil {
+		return errors.Wrap(err, "soa failed")
+	}
+	return nil
+}
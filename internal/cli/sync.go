@@ -1,34 +1,108 @@
 package cli
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
 	"github.com/strongdm/comply/internal/config"
 	"github.com/strongdm/comply/internal/model"
 	"github.com/urfave/cli"
 )
 
 var syncCommand = cli.Command{
-	Name:   "sync",
-	Usage:  "sync ticket status to local cache",
+	Name:  "sync",
+	Usage: "sync ticket status to local cache",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "full",
+			Usage: "refetch all tickets and remove tickets no longer present in the ticket system",
+		},
+	},
 	Action: syncAction,
 	Before: beforeAll(projectMustExist, ticketingMustBeConfigured),
 }
 
 func syncAction(c *cli.Context) error {
-	ts, err := config.Config().TicketSystem()
+	return syncAll(c.Bool("full"))
+}
+
+// syncAll syncs every configured ticket system.
+func syncAll(full bool) error {
+	systems, err := config.Config().TicketSystems()
 	if err != nil {
 		return cli.NewExitError("error in ticket system configuration", 1)
 	}
+	defaultTS, err := model.DefaultTicketSystem()
+	if err != nil {
+		return cli.NewExitError("error in ticket system configuration", 1)
+	}
+
+	for _, ts := range systems {
+		err = syncTickets(model.TicketSystem(ts), ts == string(defaultTS), full)
+		if err != nil {
+			return errors.Wrap(err, "unable to sync "+ts)
+		}
+	}
+	return nil
+}
+
+// syncTickets caches tickets updated since the last sync, or all tickets on a
+// full sync, pruning those no longer present.
+func syncTickets(ts model.TicketSystem, isDefault, full bool) error {
+	// tickets updated while syncing are refetched next time
+	started := time.Now()
 
-	tp := model.GetPlugin(model.TicketSystem(ts))
-	tickets, err := tp.FindByTagName("comply")
+	// caches predating cursors are fully resynced, pruning tickets cached
+	// under an earlier ID scheme such as GitLab's global issue IDs
+	cursor := model.ReadSyncCursor(ts)
+	full = full || cursor == nil
+	var since time.Time
+	if !full {
+		since = cursor.LastSync
+	}
+
+	tp := model.GetPlugin(ts)
+	tickets, err := tp.FindByTagNameSince(model.LabelComply, since)
 	if err != nil {
 		return err
 	}
+	fetched := make(map[string]bool)
 	for _, t := range tickets {
-		err = model.DB().Write("tickets", t.ID, t)
+		t.Source = ts
+		err = model.WriteTicket(t, isDefault)
 		if err != nil {
 			return err
 		}
+		fetched[t.ID] = true
 	}
-	return nil
+
+	if full {
+		fmt.Printf("Synced %d tickets from %s\n", len(tickets), ts)
+
+		cached, err := model.ReadTickets()
+		if err != nil {
+			return err
+		}
+		pruned := 0
+		for _, t := range cached {
+			// tickets cached without a source came from the default ticket system
+			fromTS := t.Source == ts || (t.Source == "" && isDefault)
+			if !fromTS || fetched[t.ID] {
+				continue
+			}
+			err = model.DeleteTicket(t)
+			if err != nil {
+				return err
+			}
+			pruned++
+		}
+		if pruned > 0 {
+			fmt.Printf("Removed %d tickets no longer present in %s\n", pruned, ts)
+		}
+	} else {
+		fmt.Printf("Synced %d tickets updated in %s since %s\n", len(tickets), ts, since.Format(time.RFC1123))
+	}
+
+	return model.WriteSyncCursor(&model.SyncCursor{TicketSystem: ts, LastSync: started})
 }
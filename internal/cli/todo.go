@@ -1,11 +1,16 @@
 package cli
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/olekukonko/tablewriter"
+	"github.com/strongdm/comply/internal/config"
 	"github.com/strongdm/comply/internal/model"
 	"github.com/urfave/cli"
 )
@@ -24,45 +29,123 @@ func todoAction(c *cli.Context) error {
 	}
 
 	w := tablewriter.NewWriter(os.Stdout)
-	w.SetHeader([]string{"Standard", "Control", "Satisfied?", "Name"})
+	w.SetHeader([]string{"Standard", "Control", "Status", "Name", "Notes"})
 
 	type row struct {
 		standard    string
 		controlKey  string
-		satisfied   string
+		status      string
 		controlName string
+		notes       string
 	}
 
 	satisfied := model.ControlsSatisfied(d)
 
 	var rows []row
+	var totals []string
 	for _, std := range d.Standards {
 		for id, c := range std.Controls {
-			sat := "NO"
-			if _, ok := satisfied[id]; ok {
-				sat = color.GreenString("YES")
+			satisfiers := satisfied[model.ControlKey{Standard: std.Name, Control: id}]
+
+			var notes []string
+			if len(satisfiers) > 0 && !model.DirectlySatisfied(satisfiers) {
+				notes = append(notes, fmt.Sprintf("via %s", satisfiers[0].Via))
+			}
+
+			status := model.EffectiveStatus(satisfiers)
+			for _, s := range satisfiers {
+				if s.Status == status && s.Justification != "" {
+					notes = append(notes, s.Justification)
+				}
 			}
 
 			rows = append(rows, row{
 				standard:    std.Name,
 				controlKey:  id,
-				satisfied:   sat,
+				status:      statusLabel(status),
 				controlName: c.Name,
+				notes:       strings.Join(notes, "; "),
 			})
 		}
+
+		cov := model.StandardCoverage(std, satisfied)
+		totals = append(totals, fmt.Sprintf("%s: %d of %d controls implemented, %d partial, %d planned, %d not applicable (%d%% coverage)",
+			std.Name, cov.Implemented, cov.Total, cov.Partial, cov.Planned, cov.NotApplicable, cov.Percent()))
 	}
 
 	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].standard != rows[j].standard {
+			return rows[i].standard < rows[j].standard
+		}
 		return rows[i].controlKey < rows[j].controlKey
 	})
 
 	w.SetAutoWrapText(false)
 
 	for _, r := range rows {
-		w.Append([]string{r.standard, r.controlKey, r.satisfied, r.controlName})
+		w.Append([]string{r.standard, r.controlKey, r.status, r.controlName, r.notes})
 	}
 
 	w.Render()
 
+	fmt.Println()
+	for _, t := range totals {
+		fmt.Println(t)
+	}
+
+	var overdue, missing, expiring int
+	var stale []string
+	for _, check := range model.CheckFreshness(d, time.Now()) {
+		switch check.State {
+		case model.EvidenceOverdue:
+			overdue++
+			stale = append(stale, fmt.Sprintf("  %s: %s since %s (%s)", check.Subject(), color.RedString("OVERDUE"), check.ExpiresAt.Format(model.DateFormat), check.Freshness))
+		case model.EvidenceMissing:
+			missing++
+			stale = append(stale, fmt.Sprintf("  %s: %s (%s)", check.Subject(), color.RedString("MISSING"), check.Freshness))
+		case model.EvidenceExpiring:
+			expiring++
+			stale = append(stale, fmt.Sprintf("  %s: %s on %s (%s)", check.Subject(), color.YellowString("EXPIRING"), check.ExpiresAt.Format(model.DateFormat), check.Freshness))
+		}
+	}
+	if len(stale) > 0 {
+		fmt.Printf("\nEvidence: %d overdue, %d missing, %d expiring soon\n", overdue, missing, expiring)
+		for _, s := range stale {
+			fmt.Println(s)
+		}
+	}
+
+	unknown := model.UnknownControls(d)
+	if len(unknown) > 0 {
+		fmt.Printf("\n%s\n", color.YellowString("The following satisfies references do not match any standard:"))
+		for _, u := range unknown {
+			rel, err := filepath.Rel(config.ProjectRoot(), u.Document)
+			if err != nil {
+				rel = u.Document
+			}
+			reason := "unknown control"
+			if u.UnknownStandard {
+				reason = "unknown standard"
+			}
+			fmt.Printf("  %s: %s:%s (%s)\n", rel, u.Standard, u.ControlKey, reason)
+		}
+	}
+
 	return nil
 }
+
+func statusLabel(status model.ControlStatus) string {
+	switch status {
+	case model.Implemented:
+		return color.GreenString("YES")
+	case model.Partial:
+		return color.YellowString("PARTIAL")
+	case model.Planned:
+		return color.YellowString("PLANNED")
+	case model.NotApplicable:
+		return color.CyanString("N/A")
+	case "":
+		return "NO"
+	}
+	return strings.ToUpper(string(status))
+}
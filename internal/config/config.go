@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"gopkg.in/yaml.v2"
 )
@@ -14,10 +15,13 @@ var projectRoot string
 var dockerAvailable, pandocAvailable bool
 
 const (
-	Jira      = "jira"
-	GitHub    = "github"
-	GitLab    = "gitlab"
-	NoTickets = "none"
+	Jira        = "jira"
+	GitHub      = "github"
+	GitLab      = "gitlab"
+	AzureDevOps = "azuredevops"
+	Linear      = "linear"
+	Local       = "local"
+	NoTickets   = "none"
 )
 
 const (
@@ -33,13 +37,26 @@ func SetProjectRoot(dir string) {
 }
 
 type Project struct {
-	Name           string                 `yaml:"name"`
-	Pandoc         string                 `yaml:"pandoc,omitempty"`
-	FilePrefix     string                 `yaml:"filePrefix"`
-	PDFFolder      string                 `yaml:"pdfFolder,omitempty"`
-	Tickets        map[string]interface{} `yaml:"tickets"`
-	ApprovedBranch string                 `yaml:"approvedBranch"`
-	CustomFolders  map[string]string      `yaml:"customFolders,omitempty"`
+	Name                string                 `yaml:"name"`
+	Pandoc              string                 `yaml:"pandoc,omitempty"`
+	FilePrefix          string                 `yaml:"filePrefix"`
+	PDFFolder           string                 `yaml:"pdfFolder,omitempty"`
+	Tickets             map[string]interface{} `yaml:"tickets"`
+	DefaultTicketSystem string                 `yaml:"defaultTicketSystem,omitempty"`
+	Labels              Labels                 `yaml:"labels,omitempty"`
+	Timezone            string                 `yaml:"timezone,omitempty"`
+	ApprovedBranch      string                 `yaml:"approvedBranch"`
+	CustomFolders       map[string]string      `yaml:"customFolders,omitempty"`
+}
+
+// Labels optionally renames the labels comply applies to tickets.
+type Labels struct {
+	// Prefix is prepended to every label, e.g. "compliance/"
+	Prefix    string `yaml:"prefix,omitempty"`
+	Comply    string `yaml:"comply,omitempty"`
+	Procedure string `yaml:"procedure,omitempty"`
+	Audit     string `yaml:"audit,omitempty"`
+	Evidence  string `yaml:"evidence,omitempty"`
 }
 
 // SetPandoc records pandoc availability during initialization
@@ -107,28 +124,46 @@ func ProjectRoot() string {
 	return projectRoot
 }
 
-// TicketSystem indicates the type of the configured ticket system
-func (p *Project) TicketSystem() (string, error) {
-	if len(p.Tickets) > 1 {
-		return NoTickets, errors.New("multiple ticket systems configured")
-	}
-
+// TicketSystems lists the configured ticket systems, ordered by name.
+func (p *Project) TicketSystems() ([]string, error) {
+	var systems []string
 	for k := range p.Tickets {
 		switch k {
-		case GitHub:
-			return GitHub, nil
-		case Jira:
-			return Jira, nil
-		case GitLab:
-			return GitLab, nil
+		case GitHub, Jira, GitLab, AzureDevOps, Linear, Local:
+			systems = append(systems, k)
 		case NoTickets:
-			return NoTickets, nil
 		default:
 			// explicit error for this case
-			return "", errors.New("unrecognized ticket system configured")
+			return nil, errors.New("unrecognized ticket system configured: " + k)
+		}
+	}
+	sort.Strings(systems)
+	return systems, nil
+}
+
+// TicketSystem indicates the type of the default ticket system, which must be
+// named by defaultTicketSystem when multiple are configured
+func (p *Project) TicketSystem() (string, error) {
+	systems, err := p.TicketSystems()
+	if err != nil {
+		return "", err
+	}
+
+	if p.DefaultTicketSystem != "" {
+		for _, ts := range systems {
+			if ts == p.DefaultTicketSystem {
+				return ts, nil
+			}
 		}
+		return NoTickets, errors.New("default ticket system " + p.DefaultTicketSystem + " is not configured")
 	}
 
-	// no ticket block configured
-	return NoTickets, nil
+	switch len(systems) {
+	case 0:
+		// no ticket block configured
+		return NoTickets, nil
+	case 1:
+		return systems[0], nil
+	}
+	return NoTickets, errors.New("multiple ticket systems configured, defaultTicketSystem must name one of them")
 }
@@ -2,8 +2,11 @@ package gitlab
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/strongdm/comply/internal/model"
@@ -36,6 +39,7 @@ type gitlabPlugin struct {
 	domain   string
 	token    string
 	reponame string
+	labels   *model.LabelVocabulary
 
 	clientMu sync.Mutex
 	client   *gitlab.Client
@@ -54,7 +58,17 @@ func (g *gitlabPlugin) api() *gitlab.Client {
 }
 
 func (g *gitlabPlugin) Get(ID string) (*model.Ticket, error) {
-	return nil, nil
+	iid, err := strconv.Atoi(ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed GitLab issue ID: "+ID)
+	}
+
+	issue, _, err := g.api().Issues.GetIssue(g.reponame, iid)
+	if err != nil {
+		return nil, errors.Wrap(err, "error during Get")
+	}
+
+	return toTicket(issue, g.labels), nil
 }
 
 func (g *gitlabPlugin) Configured() bool {
@@ -62,11 +76,14 @@ func (g *gitlabPlugin) Configured() bool {
 }
 
 func (g *gitlabPlugin) Links() model.TicketLinks {
+	audit := url.QueryEscape(g.labels.Label(model.LabelAudit))
+	procedure := url.QueryEscape(g.labels.Label(model.LabelProcedure))
+
 	links := model.TicketLinks{}
-	links.AuditAll = fmt.Sprintf("%s/%s/issues?scope=all&utf8=✓&state=all&label_name[]=comply-audit", g.domain, g.reponame)
-	links.AuditOpen = fmt.Sprintf("%s/%s/issues?scope=all&utf8=✓&state=opened&label_name[]=comply-audit", g.domain, g.reponame)
-	links.ProcedureAll = fmt.Sprintf("%s/%s/issues?scope=all&utf8=✓&state=all&label_name[]=comply-procedure", g.domain, g.reponame)
-	links.ProcedureOpen = fmt.Sprintf("%s/%s/issues?scope=all&utf8=✓&state=opened&label_name[]=comply-procedure", g.domain, g.reponame)
+	links.AuditAll = fmt.Sprintf("%s/%s/issues?scope=all&utf8=✓&state=all&label_name[]=%s", g.domain, g.reponame, audit)
+	links.AuditOpen = fmt.Sprintf("%s/%s/issues?scope=all&utf8=✓&state=opened&label_name[]=%s", g.domain, g.reponame, audit)
+	links.ProcedureAll = fmt.Sprintf("%s/%s/issues?scope=all&utf8=✓&state=all&label_name[]=%s", g.domain, g.reponame, procedure)
+	links.ProcedureOpen = fmt.Sprintf("%s/%s/issues?scope=all&utf8=✓&state=opened&label_name[]=%s", g.domain, g.reponame, procedure)
 	return links
 }
 
@@ -82,6 +99,7 @@ func (g *gitlabPlugin) Configure(cfg map[string]interface{}) error {
 	if g.reponame, err = getCfg(cfg, cfgRepo); err != nil {
 		return err
 	}
+	g.labels = model.Labels()
 
 	return nil
 }
@@ -104,70 +122,147 @@ func (g *gitlabPlugin) FindOpen() ([]*model.Ticket, error) {
 		State: gitlab.String("opened"),
 	}
 
-	issues, _, err := g.api().Issues.ListProjectIssues(g.reponame, options)
+	issues, err := g.listIssues(options)
 
 	if err != nil {
 		return nil, errors.Wrap(err, "error during FindOpen")
 	}
 
-	return toTickets(issues), nil
+	return toTickets(issues, g.labels), nil
 }
 
+// FindByTag finds tickets labeled "name:value".
 func (g *gitlabPlugin) FindByTag(name, value string) ([]*model.Ticket, error) {
-	panic("not implemented")
+	return g.FindByTagName(fmt.Sprintf("%s:%s", name, value))
 }
 
 func (g *gitlabPlugin) FindByTagName(name string) ([]*model.Ticket, error) {
+	return g.FindByTagNameSince(name, time.Time{})
+}
+
+func (g *gitlabPlugin) FindByTagNameSince(name string, since time.Time) ([]*model.Ticket, error) {
 	options := &gitlab.ListProjectIssuesOptions{
 		State:  gitlab.String("all"),
-		Labels: []string{name},
+		Labels: []string{g.labels.Label(name)},
+	}
+	if !since.IsZero() {
+		options.UpdatedAfter = &since
 	}
 
-	issues, _, err := g.api().Issues.ListProjectIssues(g.reponame, options)
+	issues, err := g.listIssues(options)
 
 	if err != nil {
-		return nil, errors.Wrap(err, "error during FindOpen")
+		return nil, errors.Wrap(err, "error during FindByTagName")
 	}
 
-	return toTickets(issues), nil
+	return toTickets(issues, g.labels), nil
+}
+
+// pageSize is the maximum page size permitted by the GitLab API.
+const pageSize = 100
+
+// backoff retries requests rejected by the GitLab rate limit.
+var backoff = &model.Backoff{System: "GitLab"}
+
+// listIssues follows pagination to list every matching issue.
+func (g *gitlabPlugin) listIssues(options *gitlab.ListProjectIssuesOptions) ([]*gitlab.Issue, error) {
+	options.PerPage = pageSize
+
+	var all []*gitlab.Issue
+	for {
+		var issues []*gitlab.Issue
+		var resp *gitlab.Response
+		err := backoff.Retry(func() (err error) {
+			issues, resp, err = g.api().Issues.ListProjectIssues(g.reponame, options)
+			return err
+		}, retryAfter)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, issues...)
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		options.Page = resp.NextPage
+	}
+}
+
+// retryAfter detects GitLab responding 429 Too Many Requests, waiting for the
+// interval advised by the Retry-After or RateLimit-Reset headers.
+func retryAfter(err error) (time.Duration, bool) {
+	e, ok := err.(*gitlab.ErrorResponse)
+	if !ok || e.Response == nil || e.Response.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(e.Response.Header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if reset, err := strconv.ParseInt(e.Response.Header.Get("RateLimit-Reset"), 10, 64); err == nil {
+		return time.Until(time.Unix(reset, 0)), true
+	}
+	return 0, true
 }
 
 func (g *gitlabPlugin) LinkFor(t *model.Ticket) string {
-	panic("not implemented")
+	return fmt.Sprintf("%s/%s/issues/%s", g.domain, g.reponame, t.ID)
 }
 
+// Create assigns the ticket by username. GitLab has no priorities or
+// components, so these become "priority:value" and "component:value" labels.
 func (g *gitlabPlugin) Create(ticket *model.Ticket, labels []string) error {
+	if ticket.Priority != "" {
+		labels = append(labels, "priority:"+ticket.Priority)
+	}
+	if ticket.Component != "" {
+		labels = append(labels, "component:"+ticket.Component)
+	}
+	labels = g.labels.Labels(labels)
 	options := &gitlab.CreateIssueOptions{
 		Title:       gitlab.String(ticket.Name),
 		Description: gitlab.String(ticket.Body),
 		Labels:      labels,
 	}
+	if ticket.Assignee != "" {
+		users, _, err := g.api().Users.ListUsers(&gitlab.ListUsersOptions{Username: gitlab.String(ticket.Assignee)})
+		if err != nil {
+			return errors.Wrap(err, "unable to find assignee")
+		}
+		if len(users) == 0 {
+			return errors.New("unknown GitLab user: " + ticket.Assignee)
+		}
+		options.AssigneeIDs = []int{users[0].ID}
+	}
+	if ticket.DueDate != nil {
+		due := gitlab.ISOTime(*ticket.DueDate)
+		options.DueDate = &due
+	}
 	_, _, err := g.api().Issues.CreateIssue(g.reponame, options)
 	return err
 }
 
-func toTickets(issues []*gitlab.Issue) []*model.Ticket {
+func toTickets(issues []*gitlab.Issue, v *model.LabelVocabulary) []*model.Ticket {
 	var tickets []*model.Ticket
 	for _, i := range issues {
-		tickets = append(tickets, toTicket(i))
+		tickets = append(tickets, toTicket(i, v))
 	}
 	return tickets
 }
 
-func toTicket(i *gitlab.Issue) *model.Ticket {
+func toTicket(i *gitlab.Issue, v *model.LabelVocabulary) *model.Ticket {
 	t := &model.Ticket{Attributes: make(map[string]interface{})}
-	t.ID = strconv.Itoa(i.ID)
+	// the project-scoped IID identifies issues in URLs and the API
+	t.ID = strconv.Itoa(i.IID)
 	t.Name = i.Title
 	t.Body = i.Description
 	t.CreatedAt = i.CreatedAt
+	t.UpdatedAt = i.UpdatedAt
+	t.ClosedAt = i.ClosedAt
 	t.State = toState(i.State)
 
 	for _, l := range i.Labels {
-		if l == "audit" {
-			t.SetBool("comply-audit")
-		}
-		if l == "procedure" {
-			t.SetBool("comply-procedure")
+		if canonical, ok := v.Canonical(l); ok {
+			t.SetBool(canonical)
 		}
 	}
 	return t
@@ -1,9 +1,299 @@
 package gitlab
 
 import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/strongdm/comply/internal/config"
+	"github.com/strongdm/comply/internal/model"
+	"github.com/xanzy/go-gitlab"
 )
 
+const issuesPath = "/api/v4/projects/acme%2Fcompliance/issues"
+
+// newTestPlugin serves recorded responses from testdata, keyed by request
+// method and escaped path, with listings keyed by their labels and state.
+func newTestPlugin(t *testing.T, fixtures map[string]string) (*gitlabPlugin, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.EscapedPath()
+		if r.Method == "GET" && r.URL.RawQuery != "" {
+			key += "?" + r.URL.Query().Encode()
+		}
+		fixture, ok := fixtures[key]
+		if !ok {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			http.NotFound(w, r)
+			return
+		}
+		body, err := ioutil.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "POST" {
+			w.WriteHeader(http.StatusCreated)
+		}
+		w.Write(body)
+	}))
+
+	client := gitlab.NewClient(nil, "token")
+	client.SetBaseURL(server.URL)
+	g := &gitlabPlugin{domain: server.URL, token: "token", reponame: "acme/compliance", client: client}
+	return g, server.Close
+}
+
+// createOne creates a ticket against the recorded fixtures.
+func createOne(t *testing.T) {
+	g, done := newTestPlugin(t, map[string]string{
+		"POST " + issuesPath: "create.json",
+	})
+	defer done()
+
+	err := g.Create(&model.Ticket{Name: "Apply OS patches", Body: "Procedure-ID: patch"}, []string{"comply", "comply-procedure"})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestGitlab(t *testing.T) {
-	createOne()
+	createOne(t)
+}
+
+func TestCreate(t *testing.T) {
+	var created map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.EscapedPath() {
+		case "GET /api/v4/users":
+			if r.URL.Query().Get("username") == "jdoe" {
+				fmt.Fprint(w, `[{"id": 42, "username": "jdoe"}]`)
+			} else {
+				fmt.Fprint(w, `[]`)
+			}
+		case "POST " + issuesPath:
+			json.NewDecoder(r.Body).Decode(&created)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"iid": 13}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := gitlab.NewClient(nil, "token")
+	client.SetBaseURL(server.URL)
+	g := &gitlabPlugin{domain: server.URL, token: "token", reponame: "acme/compliance", client: client}
+
+	due := time.Date(2018, 6, 15, 12, 0, 0, 0, time.UTC)
+	ticket := &model.Ticket{
+		Name:      "Apply OS patches",
+		Body:      "Procedure-ID: patch",
+		Assignee:  "jdoe",
+		Priority:  "high",
+		Component: "infra",
+		DueDate:   &due,
+	}
+	if err := g.Create(ticket, []string{"comply", "comply-procedure"}); err != nil {
+		t.Fatal(err)
+	}
+	if created["labels"] != "comply,comply-procedure,priority:high,component:infra" {
+		t.Errorf("unexpected labels: %v", created["labels"])
+	}
+	if fmt.Sprint(created["assignee_ids"]) != "[42]" || created["due_date"] != "2018-06-15" {
+		t.Errorf("unexpected issue: %v", created)
+	}
+
+	ticket.Assignee = "nobody"
+	if err := g.Create(ticket, []string{"comply"}); err == nil {
+		t.Error("expected unknown assignee error")
+	}
+}
+
+func TestGet(t *testing.T) {
+	g, done := newTestPlugin(t, map[string]string{
+		"GET " + issuesPath + "/12": "issue.json",
+	})
+	defer done()
+
+	ticket, err := g.Get("12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.ID != "12" || ticket.ProcedureID() != "patch" || ticket.State != model.Open || !ticket.Bool("comply-procedure") {
+		t.Errorf("unexpected ticket: %+v", ticket)
+	}
+	if link := g.LinkFor(ticket); link != g.domain+"/acme/compliance/issues/12" {
+		t.Errorf("unexpected link: %s", link)
+	}
+
+	if _, err := g.Get("COMP-12"); err == nil {
+		t.Error("expected malformed ID error")
+	}
+}
+
+func TestFind(t *testing.T) {
+	g, done := newTestPlugin(t, map[string]string{
+		"GET " + issuesPath + "?per_page=100&state=opened":                       "issues.json",
+		"GET " + issuesPath + "?labels=procedure%3Apatch&per_page=100&state=all": "issues.json",
+	})
+	defer done()
+
+	open, err := g.FindOpen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(open) != 2 {
+		t.Errorf("expected 2 tickets, got %d", len(open))
+	}
+
+	tagged, err := g.FindByTag("procedure", "patch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tagged) != 2 || tagged[1].ID != "3" || tagged[1].State != model.Closed {
+		t.Errorf("unexpected tickets: %v", tagged)
+	}
+}
+
+func TestFindSince(t *testing.T) {
+	g, done := newTestPlugin(t, map[string]string{
+		"GET " + issuesPath + "?labels=comply&per_page=100&state=all&updated_after=2018-04-01T00%3A00%3A00Z": "issues.json",
+	})
+	defer done()
+
+	tickets, err := g.FindByTagNameSince("comply", time.Date(2018, 4, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 2 {
+		t.Fatalf("expected 2 tickets, got %d", len(tickets))
+	}
+	if tickets[0].UpdatedAt == nil || tickets[0].ClosedAt != nil {
+		t.Errorf("unexpected timestamps for open ticket: %+v", tickets[0])
+	}
+	if tickets[1].ClosedAt == nil || !tickets[1].ClosedAt.Equal(time.Date(2018, 4, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected timestamps for closed ticket: %+v", tickets[1])
+	}
+}
+
+func TestFindPaginates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("per_page") != "100" {
+			t.Errorf("unexpected page size: %s", r.URL)
+		}
+		switch page := r.URL.Query().Get("page"); page {
+		case "", "1":
+			w.Header().Set("X-Next-Page", "2")
+			fmt.Fprint(w, `[{"iid": 3, "state": "opened"}, {"iid": 2, "state": "opened"}]`)
+		case "2":
+			fmt.Fprint(w, `[{"iid": 1, "state": "closed"}]`)
+		default:
+			t.Errorf("unexpected page: %s", page)
+		}
+	}))
+	defer server.Close()
+
+	client := gitlab.NewClient(nil, "token")
+	client.SetBaseURL(server.URL)
+	g := &gitlabPlugin{domain: server.URL, token: "token", reponame: "acme/compliance", client: client}
+
+	tickets, err := g.FindByTagName("comply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 3 || tickets[2].ID != "1" {
+		t.Errorf("expected tickets from both pages, got %v", tickets)
+	}
+}
+
+func TestFindBacksOff(t *testing.T) {
+	var waits []time.Duration
+	backoff.Sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { backoff.Sleep = nil }()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			w.Header().Set("Retry-After", "20")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"message": "Retry later"}`)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"message": "Retry later"}`)
+		default:
+			fmt.Fprint(w, `[{"iid": 1, "state": "opened"}]`)
+		}
+	}))
+	defer server.Close()
+
+	client := gitlab.NewClient(nil, "token")
+	client.SetBaseURL(server.URL)
+	g := &gitlabPlugin{domain: server.URL, token: "token", reponame: "acme/compliance", client: client}
+
+	tickets, err := g.FindOpen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 1 {
+		t.Errorf("expected 1 ticket, got %d", len(tickets))
+	}
+	if len(waits) != 2 || waits[0] != 20*time.Second || waits[1] != 2*time.Second {
+		t.Errorf("unexpected backoff: %v", waits)
+	}
+}
+
+func TestLabelVocabulary(t *testing.T) {
+	var labels string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			created := &struct {
+				Labels string `json:"labels"`
+			}{}
+			json.NewDecoder(r.Body).Decode(created)
+			labels = created.Labels
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"iid": 13}`)
+		case "GET":
+			if r.URL.Query().Get("labels") != "compliance/comply" {
+				t.Errorf("unexpected query: %s", r.URL)
+			}
+			issue, _ := json.Marshal([]map[string]interface{}{{"iid": 13, "state": "opened", "labels": strings.Split(labels, ",")}})
+			w.Write(issue)
+		}
+	}))
+	defer server.Close()
+
+	client := gitlab.NewClient(nil, "token")
+	client.SetBaseURL(server.URL)
+	v := model.NewLabelVocabulary(config.Labels{Prefix: "compliance/"})
+	g := &gitlabPlugin{domain: server.URL, token: "token", reponame: "acme/compliance", client: client, labels: v}
+
+	err := g.Create(&model.Ticket{Name: "Apply OS patches"}, []string{model.LabelComply, model.LabelProcedure})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if labels != "compliance/comply,compliance/comply-procedure" {
+		t.Errorf("unexpected labels: %s", labels)
+	}
+
+	tickets, err := g.FindByTagName(model.LabelComply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 1 || !tickets[0].Bool(model.LabelProcedure) {
+		t.Errorf("expected procedure ticket, got %+v", tickets)
+	}
+	if link := g.Links().ProcedureOpen; !strings.HasSuffix(link, "label_name[]=compliance%2Fcomply-procedure") {
+		t.Errorf("unexpected link: %s", link)
+	}
 }
@@ -2,6 +2,9 @@ package jira
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
 	"sync"
 	"time"
 
@@ -43,6 +46,7 @@ type jiraPlugin struct {
 	url      string
 	project  string
 	taskType string
+	labels   *model.LabelVocabulary
 
 	clientMu sync.Mutex
 	client   *jira.Client
@@ -65,7 +69,11 @@ func (j *jiraPlugin) api() *jira.Client {
 }
 
 func (j *jiraPlugin) Get(ID string) (*model.Ticket, error) {
-	return nil, nil
+	issue, _, err := j.api().Issue.Get(ID, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to fetch Jira issue "+ID)
+	}
+	return toTicket(issue, j.labels), nil
 }
 
 func (j *jiraPlugin) Configured() bool {
@@ -73,14 +81,22 @@ func (j *jiraPlugin) Configured() bool {
 }
 
 func (j *jiraPlugin) Links() model.TicketLinks {
+	audit := fmt.Sprintf("labels = %q", j.labels.Label(model.LabelAudit))
+	procedure := fmt.Sprintf("labels = %q", j.labels.Label(model.LabelProcedure))
+
 	links := model.TicketLinks{}
-	links.ProcedureAll = fmt.Sprintf("%s/issues/?jql=labels+=+comply-procedure", j.url)
-	links.ProcedureOpen = fmt.Sprintf("%s/issues/?jql=labels+=+comply-procedure+AND+resolution+=+Unresolved", j.url)
-	// links.AuditAll = fmt.Sprintf("%s/issues?q=is%3Aissue+is%3Aopen+label%3Acomply+label%3Aaudit", j.url)
-	// links.AuditOpen = fmt.Sprintf("%s/issues?q=is%3Aissue+is%3Aopen+label%3Acomply+label%3Aaudit", j.url)
+	links.AuditAll = j.searchLink(audit)
+	links.AuditOpen = j.searchLink(audit + " AND resolution = Unresolved")
+	links.ProcedureAll = j.searchLink(procedure)
+	links.ProcedureOpen = j.searchLink(procedure + " AND resolution = Unresolved")
 	return links
 }
 
+// searchLink links to the issues matching jql.
+func (j *jiraPlugin) searchLink(jql string) string {
+	return fmt.Sprintf("%s/issues/?jql=%s", j.url, url.QueryEscape(jql))
+}
+
 func (j *jiraPlugin) Configure(cfg map[string]interface{}) error {
 	var err error
 
@@ -99,6 +115,7 @@ func (j *jiraPlugin) Configure(cfg map[string]interface{}) error {
 	if j.taskType, err = getCfg(cfg, cfgTaskType); err != nil {
 		return err
 	}
+	j.labels = model.Labels()
 
 	return nil
 }
@@ -117,23 +134,75 @@ func getCfg(cfg map[string]interface{}, k string) (string, error) {
 }
 
 func (j *jiraPlugin) FindOpen() ([]*model.Ticket, error) {
-	panic("not implemented")
+	return j.search(fmt.Sprintf("project=%q AND resolution=Unresolved", j.project))
 }
 
+// FindByTag finds tickets labeled "name:value".
 func (j *jiraPlugin) FindByTag(name, value string) ([]*model.Ticket, error) {
-	panic("not implemented")
+	return j.FindByTagName(fmt.Sprintf("%s:%s", name, value))
 }
 
 func (j *jiraPlugin) FindByTagName(name string) ([]*model.Ticket, error) {
-	issues, _, err := j.api().Issue.Search("labels=comply", &jira.SearchOptions{MaxResults: 1000})
-	if err != nil {
-		return nil, errors.Wrap(err, "unable to fetch Jira issues")
+	return j.FindByTagNameSince(name, time.Time{})
+}
+
+// FindByTagNameSince uses a relative JQL date, as absolute dates are
+// interpreted in the timezone of the Jira user.
+func (j *jiraPlugin) FindByTagNameSince(name string, since time.Time) ([]*model.Ticket, error) {
+	jql := fmt.Sprintf("labels=%q", j.labels.Label(name))
+	if !since.IsZero() {
+		minutes := int(time.Since(since)/time.Minute) + 1
+		jql += fmt.Sprintf(" AND updated>=\"-%dm\"", minutes)
+	}
+	return j.search(jql)
+}
+
+// pageSize is the maximum page size permitted by Jira Cloud.
+const pageSize = 100
+
+// backoff retries requests rejected by the Jira rate limit.
+var backoff = &model.Backoff{System: "Jira"}
+
+// search follows pagination to list every issue matching jql.
+func (j *jiraPlugin) search(jql string) ([]*model.Ticket, error) {
+	options := &jira.SearchOptions{MaxResults: pageSize}
+
+	var all []jira.Issue
+	for {
+		var issues []jira.Issue
+		var resp *jira.Response
+		err := backoff.Retry(func() (err error) {
+			issues, resp, err = j.api().Issue.Search(jql, options)
+			return err
+		}, func(err error) (time.Duration, bool) {
+			return retryAfter(resp, err)
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to fetch Jira issues")
+		}
+
+		all = append(all, issues...)
+		options.StartAt += len(issues)
+		if len(issues) == 0 || options.StartAt >= resp.Total {
+			return toTickets(all, j.labels), nil
+		}
+	}
+}
+
+// retryAfter detects Jira responding 429 Too Many Requests, waiting for the
+// interval advised by the Retry-After header.
+func retryAfter(resp *jira.Response, err error) (time.Duration, bool) {
+	if err == nil || resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second, true
 	}
-	return toTickets(issues), nil
+	return 0, true
 }
 
 func (j *jiraPlugin) LinkFor(t *model.Ticket) string {
-	panic("not implemented")
+	return fmt.Sprintf("%s/secure/ViewIssue.jspa?id=%s", j.url, t.ID)
 }
 
 func (j *jiraPlugin) Create(ticket *model.Ticket, labels []string) error {
@@ -147,9 +216,24 @@ func (j *jiraPlugin) Create(ticket *model.Ticket, labels []string) error {
 			},
 			Summary:     ticket.Name,
 			Description: ticket.Body,
-			Labels:      labels,
+			Labels:      j.labels.Labels(labels),
 		},
 	}
+	if ticket.Priority != "" {
+		i.Fields.Priority = &jira.Priority{Name: ticket.Priority}
+	}
+	if ticket.Component != "" {
+		i.Fields.Components = []*jira.Component{{Name: ticket.Component}}
+	}
+	// IssueFields.Assignee and Duedate do not marshal as Jira expects
+	unknowns := make(map[string]interface{})
+	if ticket.Assignee != "" {
+		unknowns["assignee"] = map[string]string{"name": ticket.Assignee}
+	}
+	if ticket.DueDate != nil {
+		unknowns["duedate"] = ticket.DueDate.Format(model.DateFormat)
+	}
+	i.Fields.Unknowns = unknowns
 
 	_, _, err := j.api().Issue.Create(&i)
 	if err != nil {
@@ -158,25 +242,33 @@ func (j *jiraPlugin) Create(ticket *model.Ticket, labels []string) error {
 	return nil
 }
 
-func toTickets(issues []jira.Issue) []*model.Ticket {
+func toTickets(issues []jira.Issue, v *model.LabelVocabulary) []*model.Ticket {
 	var tickets []*model.Ticket
 	for _, i := range issues {
-		tickets = append(tickets, toTicket(&i))
+		tickets = append(tickets, toTicket(&i, v))
 	}
 	return tickets
 }
 
-func toTicket(i *jira.Issue) *model.Ticket {
+func toTicket(i *jira.Issue, v *model.LabelVocabulary) *model.Ticket {
 	t := &model.Ticket{Attributes: make(map[string]interface{})}
 	t.ID = i.ID
 	t.Name = i.Fields.Summary
 	t.Body = i.Fields.Description
 	createdAt := time.Time(i.Fields.Created)
 	t.CreatedAt = &createdAt
+	if updatedAt := time.Time(i.Fields.Updated); !updatedAt.IsZero() {
+		t.UpdatedAt = &updatedAt
+	}
+	if closedAt := time.Time(i.Fields.Resolutiondate); !closedAt.IsZero() {
+		t.ClosedAt = &closedAt
+	}
 	t.State = toState(i.Fields.Resolution)
 
 	for _, l := range i.Fields.Labels {
-		t.SetBool(l)
+		if canonical, ok := v.Canonical(l); ok {
+			t.SetBool(canonical)
+		}
 	}
 	return t
 }
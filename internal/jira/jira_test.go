@@ -1,9 +1,237 @@
 package jira
 
 import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/strongdm/comply/internal/config"
+	"github.com/strongdm/comply/internal/model"
 )
 
+// newTestPlugin serves recorded responses from testdata, keyed by request
+// method and path, with searches keyed by their JQL.
+func newTestPlugin(t *testing.T, fixtures map[string]string) (*jiraPlugin, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.Path
+		if jql := r.URL.Query().Get("jql"); jql != "" {
+			key += " " + jql
+		}
+		fixture, ok := fixtures[key]
+		if !ok {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			http.NotFound(w, r)
+			return
+		}
+		body, err := ioutil.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "POST" {
+			w.WriteHeader(http.StatusCreated)
+		}
+		w.Write(body)
+	}))
+
+	client, _ := jira.NewClient(nil, server.URL)
+	j := &jiraPlugin{username: "user", password: "password", url: server.URL, project: "COMP", taskType: "Task", client: client}
+	return j, server.Close
+}
+
+// createOne creates a ticket against the recorded fixtures.
+func createOne(t *testing.T) {
+	j, done := newTestPlugin(t, map[string]string{
+		"POST /rest/api/2/issue/": "create.json",
+	})
+	defer done()
+
+	err := j.Create(&model.Ticket{Name: "Apply OS patches", Body: "Procedure-ID: patch"}, []string{"comply", "comply-procedure"})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestJira(t *testing.T) {
-	createOne()
+	createOne(t)
+}
+
+func TestCreate(t *testing.T) {
+	var created struct {
+		Fields map[string]interface{} `json:"fields"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&created)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id": "10003", "key": "COMP-3"}`)
+	}))
+	defer server.Close()
+
+	client, _ := jira.NewClient(nil, server.URL)
+	j := &jiraPlugin{username: "user", password: "password", url: server.URL, project: "COMP", taskType: "Task", client: client}
+
+	due := time.Date(2018, 6, 15, 12, 0, 0, 0, time.UTC)
+	err := j.Create(&model.Ticket{
+		Name:      "Apply OS patches",
+		Body:      "Procedure-ID: patch",
+		Assignee:  "jdoe",
+		Priority:  "High",
+		Component: "Infrastructure",
+		DueDate:   &due,
+	}, []string{"comply", "comply-procedure"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := created.Fields
+	if fields["duedate"] != "2018-06-15" {
+		t.Errorf("unexpected due date: %v", fields["duedate"])
+	}
+	if fmt.Sprint(fields["assignee"]) != "map[name:jdoe]" || fmt.Sprint(fields["priority"]) != "map[name:High]" || fmt.Sprint(fields["components"]) != "[map[name:Infrastructure]]" {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+}
+
+func TestGet(t *testing.T) {
+	j, done := newTestPlugin(t, map[string]string{
+		"GET /rest/api/2/issue/10002": "issue.json",
+	})
+	defer done()
+
+	ticket, err := j.Get("10002")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.ID != "10002" || ticket.ProcedureID() != "patch" || ticket.State != model.Open || !ticket.Bool("comply-procedure") {
+		t.Errorf("unexpected ticket: %+v", ticket)
+	}
+	if link := j.LinkFor(ticket); link != j.url+"/secure/ViewIssue.jspa?id=10002" {
+		t.Errorf("unexpected link: %s", link)
+	}
+}
+
+func TestFind(t *testing.T) {
+	j, done := newTestPlugin(t, map[string]string{
+		`GET /rest/api/2/search project="COMP" AND resolution=Unresolved`: "search.json",
+		`GET /rest/api/2/search labels="procedure:patch"`:                 "search.json",
+	})
+	defer done()
+
+	open, err := j.FindOpen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(open) != 2 {
+		t.Errorf("expected 2 tickets, got %d", len(open))
+	}
+
+	tagged, err := j.FindByTag("procedure", "patch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tagged) != 2 || tagged[1].State != model.Closed {
+		t.Errorf("unexpected tickets: %v", tagged)
+	}
+}
+
+func TestFindSince(t *testing.T) {
+	j, done := newTestPlugin(t, map[string]string{
+		`GET /rest/api/2/search labels="comply" AND updated>="-61m"`: "search.json",
+	})
+	defer done()
+
+	tickets, err := j.FindByTagNameSince("comply", time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 2 {
+		t.Fatalf("expected 2 tickets, got %d", len(tickets))
+	}
+	if tickets[0].UpdatedAt == nil || tickets[0].ClosedAt != nil {
+		t.Errorf("unexpected timestamps for open ticket: %+v", tickets[0])
+	}
+	if tickets[1].ClosedAt == nil || !tickets[1].ClosedAt.Equal(time.Date(2018, 4, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected timestamps for closed ticket: %+v", tickets[1])
+	}
+}
+
+func TestFindPaginates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("maxResults") != "100" {
+			t.Errorf("unexpected page size: %s", r.URL)
+		}
+		switch start := r.URL.Query().Get("startAt"); start {
+		case "0":
+			fmt.Fprint(w, `{"startAt": 0, "maxResults": 2, "total": 3, "issues": [{"id": "3", "fields": {}}, {"id": "2", "fields": {}}]}`)
+		case "2":
+			fmt.Fprint(w, `{"startAt": 2, "maxResults": 2, "total": 3, "issues": [{"id": "1", "fields": {}}]}`)
+		default:
+			t.Errorf("unexpected page: %s", start)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := jira.NewClient(nil, server.URL)
+	j := &jiraPlugin{url: server.URL, project: "COMP", client: client}
+
+	tickets, err := j.FindByTagName("comply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 3 || tickets[2].ID != "1" {
+		t.Errorf("expected tickets from both pages, got %v", tickets)
+	}
+}
+
+func TestFindBacksOff(t *testing.T) {
+	var waits []time.Duration
+	backoff.Sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { backoff.Sleep = nil }()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			w.Header().Set("Retry-After", "10")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			fmt.Fprint(w, `{"startAt": 0, "maxResults": 100, "total": 1, "issues": [{"id": "1", "fields": {}}]}`)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := jira.NewClient(nil, server.URL)
+	j := &jiraPlugin{url: server.URL, project: "COMP", client: client}
+
+	tickets, err := j.FindOpen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 1 {
+		t.Errorf("expected 1 ticket, got %d", len(tickets))
+	}
+	if len(waits) != 2 || waits[0] != 10*time.Second || waits[1] != 2*time.Second {
+		t.Errorf("unexpected backoff: %v", waits)
+	}
+}
+
+func TestLinks(t *testing.T) {
+	j := &jiraPlugin{url: "https://acme.atlassian.net", labels: model.NewLabelVocabulary(config.Labels{Prefix: "compliance/"})}
+
+	links := j.Links()
+	if expected := `https://acme.atlassian.net/issues/?jql=labels+%3D+%22compliance%2Fcomply-procedure%22+AND+resolution+%3D+Unresolved`; links.ProcedureOpen != expected {
+		t.Errorf("expected %s, got %s", expected, links.ProcedureOpen)
+	}
+	if expected := `https://acme.atlassian.net/issues/?jql=labels+%3D+%22compliance%2Faudit%22`; links.AuditAll != expected {
+		t.Errorf("expected %s, got %s", expected, links.AuditAll)
+	}
 }
@@ -0,0 +1,128 @@
+package model
+
+import "sort"
+
+// Applicability declares which controls of a standard apply to the
+// organization, as recorded in a Statement of Applicability:
+//
+//	standard: ISO27001
+//	controls:
+//	  A.9.2.1:
+//	    applicable: true
+//	    justification: Required to manage user access
+//	  A.11.1.1:
+//	    applicable: false
+//	    justification: No physical offices; infrastructure is hosted by AWS
+type Applicability struct {
+	Standard string                          `yaml:"standard"`
+	Controls map[string]ControlApplicability `yaml:"controls"`
+	FullPath string
+}
+
+// ControlApplicability is the declared applicability of a single control.
+type ControlApplicability struct {
+	// Applicable defaults to true when omitted
+	Applicable    *bool  `yaml:"applicable"`
+	Justification string `yaml:"justification"`
+}
+
+// IsApplicable indicates the control was not explicitly excluded.
+func (c ControlApplicability) IsApplicable() bool {
+	return c.Applicable == nil || *c.Applicable
+}
+
+// SoAEntry is a single row of a Statement of Applicability.
+type SoAEntry struct {
+	Standard      string
+	Key           string
+	Family        string
+	Name          string
+	Freshness     Freshness
+	Applicable    bool
+	Justification string
+	// Status is the effective satisfaction status, or empty when unsatisfied
+	Status ControlStatus
+	// Documents lists the output filenames of the implementing documents
+	Documents []string
+}
+
+// StatementOfApplicability lists every control of the named standards (or of
+// all standards when none are named), ordered by standard and control.
+// Applicability is taken from the applicability declarations, falling back to
+// documents declaring a control not-applicable.
+func StatementOfApplicability(data *Data, standards ...string) []*SoAEntry {
+	declared := make(map[ControlKey]ControlApplicability)
+	for _, a := range data.Applicability {
+		for key, c := range a.Controls {
+			declared[ControlKey{Standard: a.Standard, Control: key}] = c
+		}
+	}
+
+	included := make(map[string]bool)
+	for _, name := range standards {
+		included[name] = true
+	}
+
+	satisfied := ControlsSatisfied(data)
+
+	var entries []*SoAEntry
+	for _, std := range data.Standards {
+		if len(included) > 0 && !included[std.Name] {
+			continue
+		}
+		for key, control := range std.Controls {
+			k := ControlKey{Standard: std.Name, Control: key}
+			satisfiers := satisfied[k]
+
+			e := &SoAEntry{
+				Standard:   std.Name,
+				Key:        key,
+				Family:     control.Family,
+				Name:       control.Name,
+				Freshness:  control.Freshness,
+				Applicable: true,
+				Status:     EffectiveStatus(satisfiers),
+			}
+
+			if c, ok := declared[k]; ok {
+				e.Applicable = c.IsApplicable()
+				e.Justification = c.Justification
+			} else if e.Status == NotApplicable {
+				e.Applicable = false
+				for _, s := range satisfiers {
+					if s.Status == NotApplicable && s.Justification != "" {
+						e.Justification = s.Justification
+						break
+					}
+				}
+			}
+
+			for _, s := range satisfiers {
+				if s.Status == NotApplicable || s.OutputFilename == "" || contains(e.Documents, s.OutputFilename) {
+					continue
+				}
+				e.Documents = append(e.Documents, s.OutputFilename)
+			}
+			sort.Strings(e.Documents)
+
+			entries = append(entries, e)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Standard != entries[j].Standard {
+			return entries[i].Standard < entries[j].Standard
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestStatementOfApplicability(t *testing.T) {
+	excluded := false
+	d := &Data{
+		Standards: []*Standard{
+			{Name: "ISO27001", Controls: map[string]Control{"A.9.2.1": {Name: "User registration"}, "A.9.2.2": {}, "A.11.1.1": {}, "A.12.1.1": {}}},
+			{Name: "TSC", Controls: map[string]Control{"CC6.1": {}}},
+		},
+		Policies: []*Document{
+			{OutputFilename: "AP.pdf", Satisfies: Satisfaction{"ISO27001": {{Key: "A.9.2.1", Status: Implemented}, {Key: "A.9.2.2", Status: Partial}}}},
+			{OutputFilename: "VP.pdf", Satisfies: Satisfaction{"ISO27001": {{Key: "A.12.1.1", Status: NotApplicable, Justification: "No vendors"}}}},
+		},
+		Applicability: []*Applicability{
+			{Standard: "ISO27001", Controls: map[string]ControlApplicability{"A.11.1.1": {Applicable: &excluded, Justification: "No offices"}}},
+		},
+	}
+
+	entries := StatementOfApplicability(d, "ISO27001")
+	expected := []SoAEntry{
+		{Standard: "ISO27001", Key: "A.11.1.1", Applicable: false, Justification: "No offices"},
+		{Standard: "ISO27001", Key: "A.12.1.1", Applicable: false, Justification: "No vendors", Status: NotApplicable},
+		{Standard: "ISO27001", Key: "A.9.2.1", Name: "User registration", Applicable: true, Status: Implemented},
+		{Standard: "ISO27001", Key: "A.9.2.2", Applicable: true, Status: Partial},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d", len(expected), len(entries))
+	}
+	for i, e := range expected {
+		got := entries[i]
+		if got.Standard != e.Standard || got.Key != e.Key || got.Name != e.Name || got.Applicable != e.Applicable || got.Justification != e.Justification || got.Status != e.Status {
+			t.Errorf("expected %+v, got %+v", e, got)
+		}
+	}
+	if len(entries[2].Documents) != 1 || entries[2].Documents[0] != "AP.pdf" {
+		t.Errorf("expected A.9.2.1 to be implemented by AP.pdf, got %v", entries[2].Documents)
+	}
+	if len(entries[1].Documents) != 0 {
+		t.Errorf("expected not-applicable declarations to be omitted from implementing documents, got %v", entries[1].Documents)
+	}
+}
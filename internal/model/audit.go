@@ -1,6 +1,112 @@
 package model
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Audit is an engagement with an external auditor, declared in the audits folder:
+//
+//	id: soc2-2018
+//	name: SOC 2 Type II 2018
+//	auditor: Example LLP
+//	period:
+//	  start: 2018-01-01
+//	  end: 2018-12-31
+//	standards:
+//	  - TSC
+//	requests:
+//	  - id: R1
+//	    name: Access reviews
+//	    description: Provide evidence of quarterly access reviews
+//	    controls:
+//	      - TSC:CC6.2
 type Audit struct {
-	ID   string
-	Name string
+	ID        string          `yaml:"id"`
+	Name      string          `yaml:"name"`
+	Auditor   string          `yaml:"auditor"`
+	Period    AuditPeriod     `yaml:"period"`
+	Standards []string        `yaml:"standards"`
+	Requests  []*AuditRequest `yaml:"requests"`
+	FullPath  string
+}
+
+// AuditPeriod is the time span under review.
+type AuditPeriod struct {
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+}
+
+func (p AuditPeriod) String() string {
+	if p.Start == "" && p.End == "" {
+		return ""
+	}
+	return p.Start + " to " + p.End
+}
+
+// DateFormat is the format of audit period and evidence dates.
+const DateFormat = "2006-01-02"
+
+// Bounds parses the period; ok is false unless both dates are valid.
+func (p AuditPeriod) Bounds() (start, end time.Time, ok bool) {
+	start, err := time.Parse(DateFormat, p.Start)
+	if err != nil {
+		return start, end, false
+	}
+	end, err = time.Parse(DateFormat, p.End)
+	if err != nil {
+		return start, end, false
+	}
+	return start, end, true
+}
+
+// Contains indicates t falls within the period, inclusive of its last day.
+func (p AuditPeriod) Contains(t time.Time) bool {
+	start, end, ok := p.Bounds()
+	if !ok {
+		return false
+	}
+	return !t.Before(start) && t.Before(end.AddDate(0, 0, 1))
+}
+
+// CurrentAudit is the audit whose period includes now, otherwise the audit
+// that ended most recently, or nil when no audit declares a valid period.
+func CurrentAudit(audits []*Audit, now time.Time) *Audit {
+	var current *Audit
+	var currentEnd time.Time
+	for _, a := range audits {
+		if a.Period.Contains(now) {
+			return a
+		}
+		_, end, ok := a.Period.Bounds()
+		if !ok || end.After(now) {
+			continue
+		}
+		if current == nil || end.After(currentEnd) {
+			current = a
+			currentEnd = end
+		}
+	}
+	return current
+}
+
+// AuditRequest is a request for evidence made by the auditor.
+type AuditRequest struct {
+	ID          string   `yaml:"id"`
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Controls    []string `yaml:"controls"`
+}
+
+// Ticket creates an evidence-request ticket, to be labeled "comply" and "audit".
+func (r *AuditRequest) Ticket(audit *Audit) *Ticket {
+	body := r.Description
+	if len(r.Controls) > 0 {
+		body += fmt.Sprintf("\n\nControls: %s", strings.Join(r.Controls, ", "))
+	}
+	return &Ticket{
+		Name: fmt.Sprintf("%s: %s", audit.Name, r.Name),
+		Body: WithTrailers(body, Trailer{Key: "Audit-ID", Value: audit.ID}, Trailer{Key: "Request-ID", Value: r.ID}),
+	}
 }
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestAuditRequestTicket(t *testing.T) {
+	a := &Audit{ID: "soc2-2018", Name: "SOC 2"}
+	r := &AuditRequest{ID: "R1", Name: "Access reviews", Description: "Provide access reviews", Controls: []string{"TSC:CC6.2"}}
+
+	ticket := r.Ticket(a)
+	if ticket.Name != "SOC 2: Access reviews" {
+		t.Errorf("unexpected ticket name %q", ticket.Name)
+	}
+	if ticket.AuditID() != "soc2-2018" || ticket.RequestID() != "R1" {
+		t.Errorf("expected audit and request IDs in ticket body, got %q", ticket.Body)
+	}
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// MaxRetries bounds how many times a rate-limited request is retried.
+const MaxRetries = 5
+
+// RetryAfter reports whether a request was rejected by a rate limit, and the
+// wait advised by the ticket system, or zero to back off exponentially.
+type RetryAfter func(err error) (wait time.Duration, limited bool)
+
+// Backoff retries requests to a ticket system while it reports a rate limit.
+type Backoff struct {
+	// System names the ticket system in messages
+	System string
+	// Sleep is replaced in tests
+	Sleep func(time.Duration)
+}
+
+// Retry calls call until retryAfter no longer reports a rate limit or
+// MaxRetries retries have been made, returning the last error.
+func (b *Backoff) Retry(call func() error, retryAfter RetryAfter) error {
+	for attempt := 0; ; attempt++ {
+		err := call()
+
+		wait, limited := retryAfter(err)
+		if !limited || attempt == MaxRetries {
+			return err
+		}
+		if wait == 0 {
+			wait = time.Second << uint(attempt)
+		}
+		if wait < time.Second {
+			wait = time.Second
+		}
+
+		fmt.Fprintf(os.Stderr, "%s rate limit exceeded, retrying in %s\n", b.System, wait.Round(time.Second))
+		sleep := b.Sleep
+		if sleep == nil {
+			sleep = time.Sleep
+		}
+		sleep(wait)
+	}
+}
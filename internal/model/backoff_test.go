@@ -0,0 +1,45 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoffRetry(t *testing.T) {
+	var waits []time.Duration
+	b := &Backoff{System: "Test", Sleep: func(d time.Duration) { waits = append(waits, d) }}
+
+	limit := errors.New("rate limited")
+	calls := 0
+	err := b.Retry(func() error {
+		calls++
+		return limit
+	}, func(err error) (time.Duration, bool) {
+		return 0, err == limit
+	})
+	if err != limit {
+		t.Errorf("expected the last error once retries are exhausted, got %v", err)
+	}
+	if calls != MaxRetries+1 {
+		t.Errorf("expected %d calls, got %d", MaxRetries+1, calls)
+	}
+	if len(waits) != MaxRetries || waits[0] != time.Second || waits[MaxRetries-1] != 16*time.Second {
+		t.Errorf("expected exponential backoff, got %v", waits)
+	}
+
+	waits = nil
+	calls = 0
+	err = b.Retry(func() error {
+		calls++
+		if calls == 1 {
+			return limit
+		}
+		return nil
+	}, func(err error) (time.Duration, bool) {
+		return 30 * time.Second, err == limit
+	})
+	if err != nil || calls != 2 || len(waits) != 1 || waits[0] != 30*time.Second {
+		t.Errorf("expected one retry after the advised wait, got %v after %d calls and waits %v", err, calls, waits)
+	}
+}
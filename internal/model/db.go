@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/nanobox-io/golang-scribble"
 	"github.com/strongdm/comply/internal/config"
@@ -34,3 +35,64 @@ func DB() *scribble.Driver {
 	})
 	return dbSingleton
 }
+
+// WriteTicket caches the ticket, keyed by its source and ID. Tickets cached
+// before sources were recorded came from the default ticket system, so only
+// its tickets replace them.
+func WriteTicket(t *Ticket, fromDefault bool) error {
+	if err := DB().Write("tickets", ticketKey(t), t); err != nil {
+		return err
+	}
+	if fromDefault && t.Source != "" && t.ID != "" {
+		return deleteCached("tickets", t.ID)
+	}
+	return nil
+}
+
+// deleteCached removes the resource from the cache, if present.
+func deleteCached(collection, resource string) error {
+	_, err := os.Stat(filepath.Join(config.ProjectRoot(), ".comply", "cache", collection, resource+".json"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return DB().Delete(collection, resource)
+}
+
+// DeleteTicket removes the ticket from the cache.
+func DeleteTicket(t *Ticket) error {
+	if t.ID == "" {
+		// an empty resource would delete the whole collection
+		return nil
+	}
+	return DB().Delete("tickets", ticketKey(t))
+}
+
+func ticketKey(t *Ticket) string {
+	if t.Source == "" {
+		return t.ID
+	}
+	return string(t.Source) + "-" + t.ID
+}
+
+// SyncCursor records when tickets were last synced from a ticket system.
+type SyncCursor struct {
+	TicketSystem TicketSystem
+	LastSync     time.Time
+}
+
+// ReadSyncCursor returns the cursor for the ticket system, or nil if it has never been synced.
+func ReadSyncCursor(ts TicketSystem) *SyncCursor {
+	cursor := &SyncCursor{}
+	if err := DB().Read("sync", string(ts), cursor); err != nil {
+		return nil
+	}
+	return cursor
+}
+
+// WriteSyncCursor records the cursor for its ticket system.
+func WriteSyncCursor(cursor *SyncCursor) error {
+	return DB().Write("sync", string(cursor.TicketSystem), cursor)
+}
@@ -33,3 +33,45 @@ func TestSaveGet(t *testing.T) {
 		t.Error("failed to read ticket")
 	}
 }
+
+func TestWriteTicket(t *testing.T) {
+	dir := os.TempDir()
+	config.SetProjectRoot(dir)
+
+	err := DB().Write("tickets", "200", &Ticket{ID: "200", Name: "cached before sources"})
+	if err != nil {
+		panic(err)
+	}
+
+	// a ticket from another system with the same ID leaves the default system's copy
+	gh := &Ticket{ID: "200", Name: "from github", Source: TicketSystem("github")}
+	if err = WriteTicket(gh, false); err != nil {
+		t.Fatal(err)
+	}
+	if err = DB().Read("tickets", "200", &Ticket{}); err != nil {
+		t.Errorf("expected legacy ticket from the default system to remain: %v", err)
+	}
+
+	jira := &Ticket{ID: "200", Name: "from jira", Source: TicketSystem("jira")}
+	if err = WriteTicket(jira, true); err != nil {
+		t.Fatal(err)
+	}
+	if err = DB().Read("tickets", "200", &Ticket{}); err == nil {
+		t.Error("expected legacy ticket to be replaced")
+	}
+	ticket := &Ticket{}
+	if err = DB().Read("tickets", "jira-200", ticket); err != nil || ticket.Name != "from jira" {
+		t.Errorf("expected tickets with the same ID from each source, got %+v (%v)", ticket, err)
+	}
+	if err = WriteTicket(jira, true); err != nil {
+		t.Errorf("expected no error without a legacy ticket, got %v", err)
+	}
+
+	if err = DeleteTicket(gh); err != nil {
+		t.Fatal(err)
+	}
+	if err = DB().Read("tickets", "github-200", &Ticket{}); err == nil {
+		t.Error("expected ticket to be deleted")
+	}
+	DeleteTicket(jira)
+}
@@ -0,0 +1,159 @@
+package model
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Evidence is a file demonstrating the operation of controls, stored in the
+// evidence folder as period/control/file with an optional YAML sidecar named
+// after the file (e.g. access-review.png.yml):
+//
+//	date: 2018-03-31
+//	collector: jane@example.com
+//	description: Quarterly access review
+//	controls:
+//	  - TSC:CC6.2
+//	procedures:
+//	  - access-review
+//
+// Without a sidecar date, evidence is dated by the last day of its period
+// folder, named YYYY, YYYY-QN, YYYY-MM or YYYY-MM-DD; evidence in other
+// folders is undated and never fresh. Without sidecar controls, the control is
+// taken from the enclosing folder name.
+type Evidence struct {
+	// Path is relative to the evidence folder
+	Path   string
+	Period string
+	// Date is zero when the evidence is undated
+	Date        time.Time
+	Collector   string
+	Description string
+	// Controls are references of the form STANDARD:CONTROL, or a bare control key
+	Controls []string
+	// Procedures are the IDs of procedures the evidence demonstrates
+	Procedures []string
+	FullPath   string
+}
+
+// DateString formats the collection date, or "undated".
+func (e *Evidence) DateString() string {
+	if e.Date.IsZero() {
+		return "undated"
+	}
+	return e.Date.Format(DateFormat)
+}
+
+var periodRE = regexp.MustCompile(`^(\d{4})(?:-Q([1-4])|-(\d{2})(?:-(\d{2}))?)?$`)
+
+// periodEnd returns the last day of an evidence period folder.
+func periodEnd(period string) (time.Time, bool) {
+	match := periodRE.FindStringSubmatch(period)
+	if match == nil {
+		return time.Time{}, false
+	}
+	year, _ := strconv.Atoi(match[1])
+	switch {
+	case match[2] != "":
+		quarter, _ := strconv.Atoi(match[2])
+		return time.Date(year, time.Month(3*quarter+1), 0, 0, 0, 0, 0, time.UTC), true
+	case match[4] != "":
+		t, err := time.Parse(DateFormat, period)
+		return t, err == nil
+	case match[3] != "":
+		month, _ := strconv.Atoi(match[3])
+		if month < 1 || month > 12 {
+			return time.Time{}, false
+		}
+		return time.Date(year, time.Month(month+1), 0, 0, 0, 0, 0, time.UTC), true
+	}
+	return time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC), true
+}
+
+type evidenceSidecar struct {
+	Date        string   `yaml:"date"`
+	Collector   string   `yaml:"collector"`
+	Description string   `yaml:"description"`
+	Controls    []string `yaml:"controls"`
+	Procedures  []string `yaml:"procedures"`
+}
+
+// EvidenceByControl indexes evidence by the controls it supports; bare control
+// keys match that control in every standard.
+func EvidenceByControl(data *Data) map[ControlKey][]*Evidence {
+	byControl := make(map[ControlKey][]*Evidence)
+	for _, e := range data.Evidence {
+		for _, ref := range e.Controls {
+			var keys []ControlKey
+			if strings.Contains(ref, ":") {
+				k, err := parseControlKey(ref)
+				if err != nil {
+					continue
+				}
+				keys = append(keys, k)
+			} else {
+				for _, std := range data.Standards {
+					if _, ok := std.Controls[ref]; ok {
+						keys = append(keys, ControlKey{Standard: std.Name, Control: ref})
+					}
+				}
+			}
+			for _, k := range keys {
+				byControl[k] = append(byControl[k], e)
+			}
+		}
+	}
+	for _, evidence := range byControl {
+		sort.SliceStable(evidence, func(i, j int) bool {
+			return evidence[i].Date.After(evidence[j].Date)
+		})
+	}
+	return byControl
+}
+
+// ControlEvidence is the evidence collected for a single control.
+type ControlEvidence struct {
+	Key  ControlKey
+	Name string
+	// Evidence is ordered newest first
+	Evidence []*Evidence
+	// Fresh is set when evidence was collected within the audit period
+	Fresh bool
+}
+
+// EvidenceStatus reports the evidence for every applicable control of the
+// standards in scope of the audit, ordered by standard and control. Without
+// an audit all standards are in scope and any dated evidence is fresh;
+// otherwise only evidence dated within the audit period is fresh.
+func EvidenceStatus(data *Data, audit *Audit) []*ControlEvidence {
+	var standards []string
+	if audit != nil {
+		standards = audit.Standards
+	}
+
+	byControl := EvidenceByControl(data)
+
+	var status []*ControlEvidence
+	for _, entry := range StatementOfApplicability(data, standards...) {
+		if !entry.Applicable {
+			continue
+		}
+		k := ControlKey{Standard: entry.Standard, Control: entry.Key}
+		ce := &ControlEvidence{
+			Key:      k,
+			Name:     entry.Name,
+			Evidence: byControl[k],
+		}
+		for _, e := range ce.Evidence {
+			if !e.Date.IsZero() && (audit == nil || audit.Period.Contains(e.Date)) {
+				ce.Fresh = true
+				break
+			}
+		}
+		status = append(status, ce)
+	}
+	return status
+}
@@ -0,0 +1,151 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/strongdm/comply/internal/config"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse(DateFormat, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestCurrentAudit(t *testing.T) {
+	audits := []*Audit{
+		{ID: "2017", Period: AuditPeriod{Start: "2017-01-01", End: "2017-12-31"}},
+		{ID: "2018", Period: AuditPeriod{Start: "2018-01-01", End: "2018-12-31"}},
+		{ID: "undated"},
+	}
+	if a := CurrentAudit(audits, date("2018-12-31")); a == nil || a.ID != "2018" {
+		t.Errorf("expected the 2018 audit to include its last day, got %v", a)
+	}
+	if a := CurrentAudit(audits, date("2019-06-01")); a == nil || a.ID != "2018" {
+		t.Errorf("expected the most recently ended audit, got %v", a)
+	}
+	if a := CurrentAudit(audits, date("2016-06-01")); a != nil {
+		t.Errorf("expected no audit before any period, got %v", a)
+	}
+}
+
+func TestEvidenceStatus(t *testing.T) {
+	d := &Data{
+		Standards: []*Standard{
+			{Name: "TSC", Controls: map[string]Control{"CC6.1": {}, "CC6.2": {}, "CC6.3": {}, "CC9.2": {}}},
+		},
+		Policies: []*Document{
+			{OutputFilename: "VP.pdf", Satisfies: Satisfaction{"TSC": {{Key: "CC9.2", Status: NotApplicable}}}},
+		},
+		Evidence: []*Evidence{
+			{Path: "2018/CC6.1/review.csv", Date: date("2018-03-31"), Controls: []string{"CC6.1"}},
+			{Path: "2017/misc/dump.txt", Date: date("2017-03-31"), Controls: []string{"TSC:CC6.2"}},
+		},
+	}
+	audit := &Audit{Period: AuditPeriod{Start: "2018-01-01", End: "2018-12-31"}}
+
+	status := EvidenceStatus(d, audit)
+	if len(status) != 3 {
+		t.Fatalf("expected 3 applicable controls, got %d", len(status))
+	}
+	expected := []struct {
+		control  string
+		evidence int
+		fresh    bool
+	}{
+		{"CC6.1", 1, true},
+		{"CC6.2", 1, false},
+		{"CC6.3", 0, false},
+	}
+	for i, e := range expected {
+		s := status[i]
+		if s.Key.Control != e.control || len(s.Evidence) != e.evidence || s.Fresh != e.fresh {
+			t.Errorf("expected %s with %d evidence (fresh: %v), got %s with %d (fresh: %v)", e.control, e.evidence, e.fresh, s.Key.Control, len(s.Evidence), s.Fresh)
+		}
+	}
+}
+
+// readEvidence loads evidence files, keyed by path relative to the evidence
+// folder, from a temporary project.
+func readEvidence(t *testing.T, files map[string]string) []*Evidence {
+	dir, err := ioutil.TempDir("", "comply-evidence")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	config.SetProjectRoot(dir)
+	defer config.SetProjectRoot("")
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "comply.yml"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, "evidence", filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	evidence, err := ReadEvidence()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return evidence
+}
+
+func TestReadEvidence(t *testing.T) {
+	evidence := readEvidence(t, map[string]string{
+		"2017-Q1/CC6.1/review.csv":   "",
+		"2018-03/CC6.1/review.csv":   "",
+		"2018/CC6.2/scan.txt":        "",
+		"2018/CC6.2/scan.txt.yml":    "date: 2018-04-15\n",
+		"2018-05-02/CC6.2/patch.txt": "",
+		"misc/CC6.3/notes.txt":       "",
+	})
+
+	expected := map[string]string{
+		"2017-Q1/CC6.1/review.csv":   "2017-03-31",
+		"2018-03/CC6.1/review.csv":   "2018-03-31",
+		"2018/CC6.2/scan.txt":        "2018-04-15",
+		"2018-05-02/CC6.2/patch.txt": "2018-05-02",
+		"misc/CC6.3/notes.txt":       "undated",
+	}
+	if len(evidence) != len(expected) {
+		t.Fatalf("expected %d evidence files, got %d", len(expected), len(evidence))
+	}
+	for _, e := range evidence {
+		if e.DateString() != expected[e.Path] {
+			t.Errorf("%s: expected %s, got %s", e.Path, expected[e.Path], e.DateString())
+		}
+	}
+
+	d := &Data{
+		Standards: []*Standard{
+			{Name: "TSC", Controls: map[string]Control{"CC6.1": {}, "CC6.3": {}}},
+		},
+		Evidence: evidence,
+	}
+	status := EvidenceStatus(d, &Audit{Period: AuditPeriod{Start: "2018-04-01", End: "2018-12-31"}})
+	for _, s := range status {
+		if s.Fresh {
+			t.Errorf("expected %s to be stale, with evidence from a past period or undated", s.Key)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Freshness is how recently evidence must have been collected, either a named
+// interval (daily, weekly, monthly, quarterly, semiannually, annually) or a
+// number of days, weeks, months or years such as "90d", "2w", "6m" or "1y".
+type Freshness string
+
+var freshnessRE = regexp.MustCompile(`^(\d+)\s*([dwmy])$`)
+
+var namedFreshness = map[Freshness]Freshness{
+	"daily":        "1d",
+	"weekly":       "1w",
+	"monthly":      "1m",
+	"quarterly":    "3m",
+	"semiannually": "6m",
+	"annually":     "1y",
+	"yearly":       "1y",
+}
+
+// Expires returns when evidence collected at the given time becomes stale.
+func (f Freshness) Expires(collected time.Time) (time.Time, error) {
+	spec := f
+	if named, ok := namedFreshness[f]; ok {
+		spec = named
+	}
+	match := freshnessRE.FindStringSubmatch(string(spec))
+	if match == nil {
+		return time.Time{}, fmt.Errorf("invalid freshness %q, must be daily, weekly, monthly, quarterly, semiannually, annually or a number of days, weeks, months or years (e.g. 90d)", string(f))
+	}
+	n, _ := strconv.Atoi(match[1])
+	switch match[2] {
+	case "d":
+		return collected.AddDate(0, 0, n), nil
+	case "w":
+		return collected.AddDate(0, 0, 7*n), nil
+	case "m":
+		return collected.AddDate(0, n, 0), nil
+	}
+	return collected.AddDate(n, 0, 0), nil
+}
+
+// EvidenceState is the result of a freshness check.
+type EvidenceState string
+
+const (
+	// EvidenceFresh evidence is within its freshness requirement.
+	EvidenceFresh = EvidenceState("fresh")
+	// EvidenceExpiring evidence becomes stale within ExpiryNotice.
+	EvidenceExpiring = EvidenceState("expiring")
+	// EvidenceOverdue evidence is stale.
+	EvidenceOverdue = EvidenceState("overdue")
+	// EvidenceMissing indicates no evidence has been collected.
+	EvidenceMissing = EvidenceState("missing")
+)
+
+// ExpiryNotice is how long before evidence becomes stale it is reported as expiring.
+const ExpiryNotice = 14 * 24 * time.Hour
+
+// FreshnessCheck evaluates the latest evidence for a control or procedure
+// against its declared freshness.
+type FreshnessCheck struct {
+	// Control is set for control requirements, ProcedureID for procedure requirements
+	Control     *ControlKey
+	ProcedureID string
+	Name        string
+	Freshness   Freshness
+	// Latest is nil when evidence is missing
+	Latest    *Evidence
+	ExpiresAt time.Time
+	State     EvidenceState
+}
+
+// Subject identifies the control or procedure requiring evidence.
+func (c *FreshnessCheck) Subject() string {
+	if c.Control != nil {
+		return c.Control.String()
+	}
+	return "procedure " + c.ProcedureID
+}
+
+// Ticket creates an evidence collection ticket, to be labeled "comply" and "comply-evidence".
+func (c *FreshnessCheck) Ticket() *Ticket {
+	var body string
+	switch c.State {
+	case EvidenceMissing:
+		body = fmt.Sprintf("No evidence has been collected for %s (%s); freshness requirement: %s.", c.Subject(), c.Name, c.Freshness)
+	case EvidenceOverdue:
+		body = fmt.Sprintf("Evidence for %s (%s) expired on %s; freshness requirement: %s.", c.Subject(), c.Name, c.ExpiresAt.Format(DateFormat), c.Freshness)
+	default:
+		body = fmt.Sprintf("Evidence for %s (%s) expires on %s; freshness requirement: %s.", c.Subject(), c.Name, c.ExpiresAt.Format(DateFormat), c.Freshness)
+	}
+	if c.Latest != nil {
+		body += fmt.Sprintf("\n\nLatest evidence: %s", c.Latest.Path)
+	}
+
+	var trailers []Trailer
+	if c.Control != nil {
+		trailers = []Trailer{{Key: "Evidence-Standard", Value: c.Control.Standard}, {Key: "Evidence-Control", Value: c.Control.Control}}
+	} else {
+		trailers = []Trailer{{Key: "Evidence-Procedure", Value: c.ProcedureID}}
+	}
+	return &Ticket{
+		Name: fmt.Sprintf("Collect evidence for %s", c.Subject()),
+		Body: WithTrailers(body, trailers...),
+	}
+}
+
+// CheckFreshness evaluates every applicable control and procedure declaring a
+// freshness requirement, ordered by controls then procedures. Invalid
+// requirements are skipped and reported by Lint.
+func CheckFreshness(data *Data, now time.Time) []*FreshnessCheck {
+	var checks []*FreshnessCheck
+
+	byControl := EvidenceByControl(data)
+	for _, entry := range StatementOfApplicability(data) {
+		if !entry.Applicable || entry.Freshness == "" {
+			continue
+		}
+		k := ControlKey{Standard: entry.Standard, Control: entry.Key}
+		c := &FreshnessCheck{Control: &k, Name: entry.Name, Freshness: entry.Freshness}
+		if c.evaluate(byControl[k], now) {
+			checks = append(checks, c)
+		}
+	}
+
+	byProcedure := make(map[string][]*Evidence)
+	for _, e := range data.Evidence {
+		for _, id := range e.Procedures {
+			byProcedure[id] = append(byProcedure[id], e)
+		}
+	}
+	for _, p := range data.Procedures {
+		if p.Freshness == "" {
+			continue
+		}
+		c := &FreshnessCheck{ProcedureID: p.ID, Name: p.Name, Freshness: p.Freshness}
+		if c.evaluate(byProcedure[p.ID], now) {
+			checks = append(checks, c)
+		}
+	}
+
+	return checks
+}
+
+// evaluate sets the state from the newest evidence, returning false when the
+// freshness requirement is invalid.
+func (c *FreshnessCheck) evaluate(evidence []*Evidence, now time.Time) bool {
+	if _, err := c.Freshness.Expires(now); err != nil {
+		return false
+	}
+	// undated evidence cannot satisfy a freshness requirement
+	var dated []*Evidence
+	for _, e := range evidence {
+		if !e.Date.IsZero() {
+			dated = append(dated, e)
+		}
+	}
+	if len(dated) == 0 {
+		c.State = EvidenceMissing
+		return true
+	}
+
+	sort.SliceStable(dated, func(i, j int) bool {
+		return dated[i].Date.After(dated[j].Date)
+	})
+	c.Latest = dated[0]
+	c.ExpiresAt, _ = c.Freshness.Expires(c.Latest.Date)
+
+	switch {
+	case !now.Before(c.ExpiresAt):
+		c.State = EvidenceOverdue
+	case c.ExpiresAt.Sub(now) <= ExpiryNotice:
+		c.State = EvidenceExpiring
+	default:
+		c.State = EvidenceFresh
+	}
+	return true
+}
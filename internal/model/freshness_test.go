@@ -0,0 +1,104 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestFreshnessExpires(t *testing.T) {
+	collected := date("2018-01-31")
+	for f, expected := range map[Freshness]string{
+		"quarterly": "2018-05-01",
+		"90d":       "2018-05-01",
+		"2w":        "2018-02-14",
+		"1y":        "2019-01-31",
+	} {
+		expires, err := f.Expires(collected)
+		if err != nil {
+			t.Errorf("%s: %v", f, err)
+			continue
+		}
+		if expires.Format(DateFormat) != expected {
+			t.Errorf("%s: expected %s, got %s", f, expected, expires.Format(DateFormat))
+		}
+	}
+
+	if _, err := Freshness("fortnightly").Expires(collected); err == nil {
+		t.Error("expected an error for an unknown freshness")
+	}
+}
+
+func TestCheckFreshness(t *testing.T) {
+	d := &Data{
+		Standards: []*Standard{
+			{Name: "TSC", Controls: map[string]Control{
+				"CC6.1": {Freshness: "quarterly"},
+				"CC6.2": {Freshness: "quarterly"},
+				"CC6.3": {Freshness: "monthly"},
+				"CC6.4": {},
+			}},
+		},
+		Procedures: []*Procedure{
+			{ID: "patch", Freshness: "monthly"},
+		},
+		Evidence: []*Evidence{
+			{Date: date("2018-03-01"), Controls: []string{"TSC:CC6.1"}},
+			{Date: date("2018-01-10"), Controls: []string{"TSC:CC6.2"}},
+			{Date: date("2018-03-20"), Procedures: []string{"patch"}},
+		},
+	}
+
+	checks := CheckFreshness(d, date("2018-04-01"))
+	expected := map[string]EvidenceState{
+		"TSC:CC6.1":       EvidenceFresh,
+		"TSC:CC6.2":       EvidenceExpiring,
+		"TSC:CC6.3":       EvidenceMissing,
+		"procedure patch": EvidenceFresh,
+	}
+	if len(checks) != len(expected) {
+		t.Fatalf("expected %d checks, got %d", len(expected), len(checks))
+	}
+	for _, c := range checks {
+		if c.State != expected[c.Subject()] {
+			t.Errorf("%s: expected %s, got %s", c.Subject(), expected[c.Subject()], c.State)
+		}
+	}
+
+	overdue := CheckFreshness(d, date("2018-04-10"))
+	if overdue[1].State != EvidenceOverdue {
+		t.Errorf("expected TSC:CC6.2 to be overdue, got %s", overdue[1].State)
+	}
+
+	ticket := overdue[1].Ticket()
+	if ticket.EvidenceFor() != "TSC:CC6.2" {
+		t.Errorf("expected evidence ticket for TSC:CC6.2, got %q", ticket.EvidenceFor())
+	}
+}
+
+func TestCheckFreshnessEvidenceDates(t *testing.T) {
+	d := &Data{
+		Standards: []*Standard{
+			{Name: "TSC", Controls: map[string]Control{
+				"CC6.1": {Freshness: "quarterly"},
+				"CC6.2": {Freshness: "quarterly"},
+				"CC6.3": {Freshness: "quarterly"},
+			}},
+		},
+		Evidence: readEvidence(t, map[string]string{
+			"2017-Q1/CC6.1/review.csv": "",
+			"2018/CC6.2/scan.txt":      "",
+			"2018/CC6.2/scan.txt.yml":  "date: 2018-04-15\n",
+			"misc/CC6.3/notes.txt":     "",
+		}),
+	}
+
+	expected := map[string]EvidenceState{
+		"TSC:CC6.1": EvidenceOverdue,
+		"TSC:CC6.2": EvidenceFresh,
+		"TSC:CC6.3": EvidenceMissing,
+	}
+	for _, c := range CheckFreshness(d, date("2018-05-01")) {
+		if c.State != expected[c.Subject()] {
+			t.Errorf("%s: expected %s, got %s", c.Subject(), expected[c.Subject()], c.State)
+		}
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/strongdm/comply/internal/config"
@@ -34,6 +36,22 @@ func ReadData() (*Data, error) {
 	if err != nil {
 		return nil, err
 	}
+	mappings, err := ReadMappings()
+	if err != nil {
+		return nil, err
+	}
+	applicability, err := ReadApplicability()
+	if err != nil {
+		return nil, err
+	}
+	audits, err := ReadAudits()
+	if err != nil {
+		return nil, err
+	}
+	evidence, err := ReadEvidence()
+	if err != nil {
+		return nil, err
+	}
 
 	return &Data{
 		Tickets:    tickets,
@@ -41,6 +59,11 @@ func ReadData() (*Data, error) {
 		Policies:   policies,
 		Procedures: procedures,
 		Standards:  standards,
+		Mappings:   mappings,
+		Audits:     audits,
+
+		Applicability: applicability,
+		Evidence:      evidence,
 	}, nil
 }
 
@@ -83,13 +106,168 @@ func ReadStandards() ([]*Standard, error) {
 			return nil, errors.Wrap(err, "unable to read "+f.FullPath)
 		}
 
-		yaml.Unmarshal(sBytes, &s)
+		err = yaml.Unmarshal(sBytes, &s)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to parse "+f.FullPath)
+		}
 		standards = append(standards, s)
 	}
 
 	return standards, nil
 }
 
+// ReadMappings loads control mappings from the filesystem.
+func ReadMappings() ([]*Mapping, error) {
+	var mappings []*Mapping
+
+	files, err := path.Mappings()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to enumerate paths")
+	}
+
+	for _, f := range files {
+		m := &Mapping{}
+		mBytes, err := ioutil.ReadFile(f.FullPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read "+f.FullPath)
+		}
+
+		err = yaml.Unmarshal(mBytes, &m)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to parse "+f.FullPath)
+		}
+		m.FullPath = f.FullPath
+		mappings = append(mappings, m)
+	}
+
+	return mappings, nil
+}
+
+// ReadApplicability loads control applicability declarations from the filesystem.
+func ReadApplicability() ([]*Applicability, error) {
+	var applicability []*Applicability
+
+	files, err := path.Applicability()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to enumerate paths")
+	}
+
+	for _, f := range files {
+		a := &Applicability{}
+		aBytes, err := ioutil.ReadFile(f.FullPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read "+f.FullPath)
+		}
+
+		err = yaml.Unmarshal(aBytes, &a)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to parse "+f.FullPath)
+		}
+		a.FullPath = f.FullPath
+		applicability = append(applicability, a)
+	}
+
+	return applicability, nil
+}
+
+// ReadAudits loads audit definitions from the filesystem.
+func ReadAudits() ([]*Audit, error) {
+	var audits []*Audit
+
+	files, err := path.Audits()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to enumerate paths")
+	}
+
+	for _, f := range files {
+		a := &Audit{}
+		aBytes, err := ioutil.ReadFile(f.FullPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read "+f.FullPath)
+		}
+
+		err = yaml.Unmarshal(aBytes, &a)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to parse "+f.FullPath)
+		}
+		a.FullPath = f.FullPath
+		audits = append(audits, a)
+	}
+
+	return audits, nil
+}
+
+// ReadEvidence loads the evidence index from the filesystem, pairing each file
+// with its optional YAML sidecar.
+func ReadEvidence() ([]*Evidence, error) {
+	var evidence []*Evidence
+
+	files, err := path.Evidence()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to enumerate paths")
+	}
+
+	present := make(map[string]bool)
+	for _, f := range files {
+		present[f.FullPath] = true
+	}
+
+	root := path.EvidenceRoot()
+	for _, f := range files {
+		// sidecars are named after the file they describe
+		if strings.HasSuffix(f.FullPath, ".yml") && present[strings.TrimSuffix(f.FullPath, ".yml")] {
+			continue
+		}
+
+		rel, err := filepath.Rel(root, f.FullPath)
+		if err != nil {
+			rel = f.FullPath
+		}
+		e := &Evidence{
+			Path:     filepath.ToSlash(rel),
+			FullPath: f.FullPath,
+		}
+		components := strings.Split(e.Path, "/")
+		if len(components) > 1 {
+			e.Period = components[0]
+			// modification times reflect the checkout, not the collection
+			if end, ok := periodEnd(e.Period); ok {
+				e.Date = end
+			}
+		}
+		if len(components) > 2 {
+			e.Controls = []string{components[1]}
+		}
+
+		if present[f.FullPath+".yml"] {
+			sidecar := &evidenceSidecar{}
+			sBytes, err := ioutil.ReadFile(f.FullPath + ".yml")
+			if err != nil {
+				return nil, errors.Wrap(err, "unable to read "+f.FullPath+".yml")
+			}
+			err = yaml.Unmarshal(sBytes, sidecar)
+			if err != nil {
+				return nil, errors.Wrap(err, "unable to parse "+f.FullPath+".yml")
+			}
+			if sidecar.Date != "" {
+				e.Date, err = time.Parse(DateFormat, sidecar.Date)
+				if err != nil {
+					return nil, errors.Wrap(err, "unable to parse date in "+f.FullPath+".yml")
+				}
+			}
+			e.Collector = sidecar.Collector
+			e.Description = sidecar.Description
+			if len(sidecar.Controls) > 0 {
+				e.Controls = sidecar.Controls
+			}
+			e.Procedures = sidecar.Procedures
+		}
+		evidence = append(evidence, e)
+	}
+
+	return evidence, nil
+}
+
 // ReadNarratives loads narrative descriptions from the filesystem.
 func ReadNarratives() ([]*Document, error) {
 	var narratives []*Document
@@ -101,7 +279,10 @@ func ReadNarratives() ([]*Document, error) {
 
 	for _, f := range files {
 		n := &Document{}
-		mdmd := loadMDMD(f.FullPath)
+		mdmd, err := loadMDMD(f.FullPath)
+		if err != nil {
+			return nil, err
+		}
 		err = yaml.Unmarshal([]byte(mdmd.yaml), &n)
 		if err != nil {
 			return nil, errors.Wrap(err, "unable to parse "+f.FullPath)
@@ -126,7 +307,10 @@ func ReadProcedures() ([]*Procedure, error) {
 
 	for _, f := range files {
 		p := &Procedure{}
-		mdmd := loadMDMD(f.FullPath)
+		mdmd, err := loadMDMD(f.FullPath)
+		if err != nil {
+			return nil, err
+		}
 		err = yaml.Unmarshal([]byte(mdmd.yaml), &p)
 		if err != nil {
 			return nil, errors.Wrap(err, "unable to parse "+f.FullPath)
@@ -134,6 +318,10 @@ func ReadProcedures() ([]*Procedure, error) {
 		p.Body = mdmd.body
 		p.FullPath = f.FullPath
 		p.ModifiedAt = f.Info.ModTime()
+		// procedures are named apart from documents, whose acronyms may match an ID
+		if p.ID != "" {
+			p.OutputFilename = fmt.Sprintf("%s-procedure-%s.pdf", config.Config().FilePrefix, p.ID)
+		}
 		procedures = append(procedures, p)
 	}
 
@@ -151,7 +339,10 @@ func ReadPolicies() ([]*Document, error) {
 
 	for _, f := range files {
 		p := &Document{}
-		mdmd := loadMDMD(f.FullPath)
+		mdmd, err := loadMDMD(f.FullPath)
+		if err != nil {
+			return nil, err
+		}
 		err = yaml.Unmarshal([]byte(mdmd.yaml), &p)
 		if err != nil {
 			return nil, errors.Wrap(err, "unable to parse "+f.FullPath)
@@ -169,20 +360,24 @@ func ReadPolicies() ([]*Document, error) {
 type metadataMarkdown struct {
 	yaml string
 	body string
+	// yamlOffset is the number of file lines preceding the YAML header
+	yamlOffset int
 }
 
-func loadMDMD(path string) metadataMarkdown {
+func loadMDMD(path string) (metadataMarkdown, error) {
 	bytes, err := ioutil.ReadFile(path)
 	if err != nil {
-		panic(err)
+		return metadataMarkdown{}, errors.Wrap(err, "unable to read "+path)
 	}
+	return parseMDMD(path, string(bytes))
+}
 
-	content := string(bytes)
+func parseMDMD(path, content string) (metadataMarkdown, error) {
 	components := strings.Split(content, "---")
 	if len(components) < 3 {
-		panic(fmt.Sprintf("Malformed metadata markdown in %s, must be of the form: YAML\\n---\\nmarkdown content", path))
+		return metadataMarkdown{}, fmt.Errorf("malformed metadata markdown in %s, must be of the form: YAML\\n---\\nmarkdown content", path)
 	}
 	yaml := components[1]
 	body := strings.Join(components[2:], "---")
-	return metadataMarkdown{yaml, body}
+	return metadataMarkdown{yaml, body, strings.Count(components[0], "\n")}, nil
 }
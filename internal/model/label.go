@@ -0,0 +1,97 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/strongdm/comply/internal/config"
+)
+
+// Canonical labels, as applied and read throughout comply. Ticket plugins
+// translate them to the project's LabelVocabulary.
+const (
+	LabelComply    = "comply"
+	LabelProcedure = "comply-procedure"
+	LabelAudit     = "audit"
+	LabelEvidence  = "comply-evidence"
+)
+
+// LabelVocabulary maps canonical labels to the labels used in the ticket
+// system. A nil vocabulary leaves labels unchanged.
+type LabelVocabulary struct {
+	prefix     string
+	toSystem   map[string]string
+	fromSystem map[string]string
+}
+
+// NewLabelVocabulary names canonical labels as configured, defaulting to
+// their canonical names.
+func NewLabelVocabulary(cfg config.Labels) *LabelVocabulary {
+	v := &LabelVocabulary{
+		prefix:     cfg.Prefix,
+		toSystem:   make(map[string]string),
+		fromSystem: make(map[string]string),
+	}
+	names := map[string]string{
+		LabelComply:    cfg.Comply,
+		LabelProcedure: cfg.Procedure,
+		LabelAudit:     cfg.Audit,
+		LabelEvidence:  cfg.Evidence,
+	}
+	for canonical, name := range names {
+		if name == "" {
+			name = canonical
+		}
+		v.toSystem[canonical] = name
+		v.fromSystem[name] = canonical
+	}
+	return v
+}
+
+// Labels is the label vocabulary of the current project.
+func Labels() *LabelVocabulary {
+	if !config.Exists() {
+		return nil
+	}
+	return NewLabelVocabulary(config.Config().Labels)
+}
+
+// Label is the ticket system label for a canonical label.
+func (v *LabelVocabulary) Label(canonical string) string {
+	if v == nil {
+		return canonical
+	}
+	if name, ok := v.toSystem[canonical]; ok {
+		return v.prefix + name
+	}
+	return v.prefix + canonical
+}
+
+// Labels are the ticket system labels for canonical labels.
+func (v *LabelVocabulary) Labels(canonical []string) []string {
+	labels := make([]string, len(canonical))
+	for i, l := range canonical {
+		labels[i] = v.Label(l)
+	}
+	return labels
+}
+
+// Canonical is the canonical label for a ticket system label. Labels without
+// the configured prefix were not applied by comply, and are unchanged. It is
+// false for a canonical label's own name once the project names it otherwise,
+// e.g. "audit" with labels.audit set, as comply did not apply it.
+func (v *LabelVocabulary) Canonical(label string) (string, bool) {
+	if v == nil {
+		return label, true
+	}
+	name := label
+	if strings.HasPrefix(label, v.prefix) {
+		name = strings.TrimPrefix(label, v.prefix)
+		if canonical, ok := v.fromSystem[name]; ok {
+			return canonical, true
+		}
+	}
+	if _, ok := v.toSystem[name]; ok {
+		return "", false
+	}
+	return name, true
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/strongdm/comply/internal/config"
+)
+
+func TestLabelVocabulary(t *testing.T) {
+	v := NewLabelVocabulary(config.Labels{Prefix: "compliance/", Procedure: "procedure", Audit: "evidence-request"})
+
+	canonical := []string{LabelComply, LabelProcedure, LabelAudit, LabelEvidence, "procedure:patch", "ops"}
+	labels := v.Labels(canonical)
+	expected := []string{"compliance/comply", "compliance/procedure", "compliance/evidence-request", "compliance/comply-evidence", "compliance/procedure:patch", "compliance/ops"}
+	if !reflect.DeepEqual(labels, expected) {
+		t.Errorf("unexpected labels: %v", labels)
+	}
+	for i, l := range labels {
+		if c, ok := v.Canonical(l); !ok || c != canonical[i] {
+			t.Errorf("expected %s to round-trip to %s, got %s", l, canonical[i], c)
+		}
+	}
+	if c, ok := v.Canonical("procedure"); !ok || c != "procedure" {
+		t.Errorf("expected label without prefix to be unchanged, got %s", c)
+	}
+	for _, l := range []string{"audit", "compliance/audit", "comply-procedure"} {
+		if c, ok := v.Canonical(l); ok {
+			t.Errorf("expected %s not to be read as a renamed canonical label, got %s", l, c)
+		}
+	}
+
+	renamed := NewLabelVocabulary(config.Labels{Audit: "compliance-audit"})
+	if c, ok := renamed.Canonical("compliance-audit"); !ok || c != LabelAudit {
+		t.Errorf("expected configured label to be read as audit, got %s", c)
+	}
+	if c, ok := renamed.Canonical(LabelAudit); ok {
+		t.Errorf("expected unrelated audit label to be ignored, got %s", c)
+	}
+	if c, ok := renamed.Canonical(LabelComply); !ok || c != LabelComply {
+		t.Errorf("expected labels left unrenamed to be read, got %s", c)
+	}
+
+	var unset *LabelVocabulary
+	if c, ok := unset.Canonical("x"); unset.Label(LabelProcedure) != LabelProcedure || !ok || c != "x" {
+		t.Error("expected nil vocabulary to leave labels unchanged")
+	}
+	if NewLabelVocabulary(config.Labels{}).Label(LabelAudit) != "audit" {
+		t.Error("expected default vocabulary to use canonical labels")
+	}
+}
@@ -0,0 +1,537 @@
+package model
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron"
+	"github.com/strongdm/comply/internal/config"
+	"github.com/strongdm/comply/internal/path"
+	"gopkg.in/yaml.v2"
+)
+
+// Severity indicates whether a Diagnostic should fail validation.
+type Severity string
+
+const (
+	// SeverityError diagnostics cause `comply lint` to exit non-zero.
+	SeverityError = Severity("error")
+	// SeverityWarning diagnostics are reported but do not fail validation.
+	SeverityWarning = Severity("warning")
+)
+
+// Lint rule identifiers.
+const (
+	RuleMalformedDocument    = "malformed-document"
+	RuleMalformedYAML        = "malformed-yaml"
+	RuleMissingName          = "missing-name"
+	RuleMissingAcronym       = "missing-acronym"
+	RuleMissingID            = "missing-id"
+	RuleDuplicateAcronym     = "duplicate-acronym"
+	RuleDuplicateProcedureID = "duplicate-procedure-id"
+	RuleInvalidCron          = "invalid-cron"
+	RuleUnknownStandard      = "unknown-standard"
+	RuleUnknownControl       = "unknown-control"
+	RuleMalformedMapping     = "malformed-mapping"
+	RuleInvalidStatus        = "invalid-status"
+	RuleMissingJustification = "missing-justification"
+	RuleDuplicateAuditID     = "duplicate-audit-id"
+	RuleDuplicateRequestID   = "duplicate-request-id"
+	RuleInvalidDate          = "invalid-date"
+	RuleInvalidFreshness     = "invalid-freshness"
+	RuleUnknownTicketSystem  = "unknown-ticket-system"
+	RuleInvalidCatchUp       = "invalid-catch-up"
+	RuleInvalidTimezone      = "invalid-timezone"
+)
+
+// Diagnostic describes a single problem found in a project file.
+type Diagnostic struct {
+	File     string
+	Line     int
+	Rule     string
+	Severity Severity
+	Message  string
+}
+
+// Lint parses all narratives, policies, procedures, standards, mappings,
+// applicability declarations, audits and evidence sidecars without panicking,
+// returning diagnostics ordered by file and line.
+func Lint() ([]*Diagnostic, error) {
+	l := &linter{
+		acronyms:     make(map[string]string),
+		procedureIDs: make(map[string]string),
+		auditIDs:     make(map[string]string),
+	}
+
+	systems, err := config.Config().TicketSystems()
+	if err != nil {
+		return nil, err
+	}
+	l.ticketSystems = make(map[string]bool)
+	for _, ts := range systems {
+		l.ticketSystems[ts] = true
+	}
+
+	standards, err := path.Standards()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to enumerate paths")
+	}
+	for _, f := range standards {
+		if err := l.lintFile(f, l.lintStandard); err != nil {
+			return nil, err
+		}
+	}
+
+	mappings, err := path.Mappings()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to enumerate paths")
+	}
+	for _, f := range mappings {
+		if err := l.lintFile(f, l.lintMapping); err != nil {
+			return nil, err
+		}
+	}
+
+	applicability, err := path.Applicability()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to enumerate paths")
+	}
+	for _, f := range applicability {
+		if err := l.lintFile(f, l.lintApplicability); err != nil {
+			return nil, err
+		}
+	}
+
+	audits, err := path.Audits()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to enumerate paths")
+	}
+	for _, f := range audits {
+		if err := l.lintFile(f, l.lintAudit); err != nil {
+			return nil, err
+		}
+	}
+
+	evidence, err := path.Evidence()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to enumerate paths")
+	}
+	present := make(map[string]bool)
+	for _, f := range evidence {
+		present[f.FullPath] = true
+	}
+	for _, f := range evidence {
+		if strings.HasSuffix(f.FullPath, ".yml") && present[strings.TrimSuffix(f.FullPath, ".yml")] {
+			if err := l.lintFile(f, l.lintEvidenceSidecar); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	narratives, err := path.Narratives()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to enumerate paths")
+	}
+	policies, err := path.Policies()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to enumerate paths")
+	}
+	for _, f := range append(narratives, policies...) {
+		if err := l.lintFile(f, l.lintDocument); err != nil {
+			return nil, err
+		}
+	}
+
+	procedures, err := path.Procedures()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to enumerate paths")
+	}
+	for _, f := range procedures {
+		if err := l.lintFile(f, l.lintProcedure); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(l.diagnostics, func(i, j int) bool {
+		if l.diagnostics[i].File != l.diagnostics[j].File {
+			return l.diagnostics[i].File < l.diagnostics[j].File
+		}
+		return l.diagnostics[i].Line < l.diagnostics[j].Line
+	})
+	return l.diagnostics, nil
+}
+
+type linter struct {
+	diagnostics []*Diagnostic
+	standards   []*Standard
+
+	// first file seen declaring each acronym, procedure ID or audit ID
+	acronyms     map[string]string
+	procedureIDs map[string]string
+	auditIDs     map[string]string
+
+	// ticket systems configured in comply.yml
+	ticketSystems map[string]bool
+}
+
+func (l *linter) report(file string, line int, rule string, severity Severity, format string, args ...interface{}) {
+	l.diagnostics = append(l.diagnostics, &Diagnostic{
+		File:     file,
+		Line:     line,
+		Rule:     rule,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) lintFile(f path.File, fn func(file, content string)) error {
+	content, err := ioutil.ReadFile(f.FullPath)
+	if err != nil {
+		return errors.Wrap(err, "unable to read "+f.FullPath)
+	}
+	rel, err := filepath.Rel(config.ProjectRoot(), f.FullPath)
+	if err != nil {
+		rel = f.FullPath
+	}
+	fn(rel, string(content))
+	return nil
+}
+
+// unmarshal decodes YAML, reporting each error at its file line; offset is the
+// number of file lines preceding the YAML.
+func (l *linter) unmarshal(file, content string, offset int, v interface{}) bool {
+	err := yaml.Unmarshal([]byte(content), v)
+	if err == nil {
+		return true
+	}
+
+	messages := []string{err.Error()}
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		messages = typeErr.Errors
+	}
+	for _, m := range messages {
+		line := 1
+		if match := yamlLineRE.FindStringSubmatch(m); match != nil {
+			line, _ = strconv.Atoi(match[1])
+		}
+		l.report(file, offset+line, RuleMalformedYAML, SeverityError, "%s", strings.TrimPrefix(m, "yaml: "))
+	}
+	return false
+}
+
+func (l *linter) lintStandard(file, content string) {
+	s := &Standard{}
+	if !l.unmarshal(file, content, 0, s) {
+		return
+	}
+	if s.Name == "" {
+		l.report(file, 1, RuleMissingName, SeverityError, "standard is missing a name")
+	}
+	for key, c := range s.Controls {
+		if c.Freshness == "" {
+			continue
+		}
+		if _, err := c.Freshness.Expires(time.Now()); err != nil {
+			l.report(file, keyLine(content, key), RuleInvalidFreshness, SeverityError, "%s:%s has an %v", s.Name, key, err)
+		}
+	}
+	l.standards = append(l.standards, s)
+}
+
+func (l *linter) lintMapping(file, content string) {
+	m := &Mapping{}
+	if !l.unmarshal(file, content, 0, m) {
+		return
+	}
+	for _, declaration := range m.Mappings {
+		line := itemLine(content, declaration)
+		parsed, err := ParseMapping(declaration)
+		if err != nil {
+			l.report(file, line, RuleMalformedMapping, SeverityError, "%v", err)
+			continue
+		}
+		// the first mapping references both controls
+		for _, k := range []ControlKey{parsed[0].From, parsed[0].To} {
+			l.lintControlKey(file, line, k)
+		}
+	}
+}
+
+func (l *linter) lintApplicability(file, content string) {
+	a := &Applicability{}
+	if !l.unmarshal(file, content, 0, a) {
+		return
+	}
+	if a.Standard == "" {
+		l.report(file, 1, RuleMissingName, SeverityError, "applicability is missing a standard")
+		return
+	}
+
+	var keys []string
+	for key := range a.Controls {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	satisfies := Satisfaction{}
+	for _, key := range keys {
+		satisfies[a.Standard] = append(satisfies[a.Standard], SatisfiedControl{Key: key})
+		if !a.Controls[key].IsApplicable() && a.Controls[key].Justification == "" {
+			l.report(file, keyLine(content, key), RuleMissingJustification, SeverityWarning, "%s:%s is not applicable but has no justification", a.Standard, key)
+		}
+	}
+	for _, u := range unknownControls(l.standards, file, satisfies) {
+		if u.UnknownStandard {
+			l.report(file, keyLine(content, "standard"), RuleUnknownStandard, SeverityError, "unknown standard %s", u.Standard)
+			return
+		}
+		l.report(file, keyLine(content, u.ControlKey), RuleUnknownControl, SeverityError, "%s:%s is not declared by standard %s", u.Standard, u.ControlKey, u.Standard)
+	}
+}
+
+func (l *linter) lintAudit(file, content string) {
+	a := &Audit{}
+	if !l.unmarshal(file, content, 0, a) {
+		return
+	}
+	if a.Name == "" {
+		l.report(file, 1, RuleMissingName, SeverityError, "audit is missing a name")
+	}
+	if a.ID == "" {
+		l.report(file, 1, RuleMissingID, SeverityError, "audit is missing an id")
+	} else if first, ok := l.auditIDs[a.ID]; ok {
+		l.report(file, keyLine(content, "id"), RuleDuplicateAuditID, SeverityError, "audit ID %s is already used by %s", a.ID, first)
+	} else {
+		l.auditIDs[a.ID] = file
+	}
+
+	for _, name := range a.Standards {
+		if !l.standardDeclared(name) {
+			l.report(file, itemLine(content, name), RuleUnknownStandard, SeverityError, "unknown standard %s", name)
+		}
+	}
+
+	requestIDs := make(map[string]int)
+	for _, r := range a.Requests {
+		if r.ID == "" {
+			l.report(file, itemLine(content, r.Name), RuleMissingID, SeverityError, "audit request %q is missing an id", r.Name)
+		} else if n := requestIDs[r.ID]; n > 0 {
+			line := 1
+			if lines := itemLines(content, r.ID); len(lines) > n {
+				line = lines[n]
+			}
+			l.report(file, line, RuleDuplicateRequestID, SeverityError, "audit request ID %s is used more than once", r.ID)
+		}
+		requestIDs[r.ID]++
+
+		for _, ref := range r.Controls {
+			line := itemLine(content, ref)
+			k, err := parseControlKey(ref)
+			if err != nil {
+				l.report(file, line, RuleUnknownControl, SeverityError, "%v", err)
+				continue
+			}
+			l.lintControlKey(file, line, k)
+		}
+	}
+}
+
+func (l *linter) lintEvidenceSidecar(file, content string) {
+	sidecar := &evidenceSidecar{}
+	if !l.unmarshal(file, content, 0, sidecar) {
+		return
+	}
+	if sidecar.Date != "" {
+		if _, err := time.Parse(DateFormat, sidecar.Date); err != nil {
+			l.report(file, keyLine(content, "date"), RuleInvalidDate, SeverityError, "invalid date %q, must be of the form YYYY-MM-DD", sidecar.Date)
+		}
+	}
+
+	for _, ref := range sidecar.Controls {
+		line := itemLine(content, ref)
+		if !strings.Contains(ref, ":") {
+			if !l.controlDeclared(ref) {
+				l.report(file, line, RuleUnknownControl, SeverityError, "%s is not declared by any standard", ref)
+			}
+			continue
+		}
+		k, err := parseControlKey(ref)
+		if err != nil {
+			l.report(file, line, RuleUnknownControl, SeverityError, "%v", err)
+			continue
+		}
+		l.lintControlKey(file, line, k)
+	}
+}
+
+// lintControlKey reports a STANDARD:CONTROL reference no standard declares.
+func (l *linter) lintControlKey(file string, line int, k ControlKey) {
+	for _, u := range unknownControls(l.standards, file, Satisfaction{k.Standard: {{Key: k.Control}}}) {
+		if u.UnknownStandard {
+			l.report(file, line, RuleUnknownStandard, SeverityError, "%s references unknown standard %s", k, k.Standard)
+		} else {
+			l.report(file, line, RuleUnknownControl, SeverityError, "%s is not declared by standard %s", k, k.Standard)
+		}
+	}
+}
+
+func (l *linter) controlDeclared(key string) bool {
+	for _, s := range l.standards {
+		if _, ok := s.Controls[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *linter) standardDeclared(name string) bool {
+	for _, s := range l.standards {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// lintSatisfies reports references to controls no standard declares; standards
+// must be linted first.
+func (l *linter) lintSatisfies(file string, mdmd metadataMarkdown, satisfies Satisfaction) {
+	for _, u := range unknownControls(l.standards, file, satisfies) {
+		if u.UnknownStandard {
+			l.report(file, mdmd.yamlOffset+keyLine(mdmd.yaml, u.Standard), RuleUnknownStandard, SeverityError, "%s:%s references unknown standard %s", u.Standard, u.ControlKey, u.Standard)
+			continue
+		}
+		l.report(file, mdmd.yamlOffset+itemLine(mdmd.yaml, u.ControlKey), RuleUnknownControl, SeverityError, "%s:%s is not declared by standard %s", u.Standard, u.ControlKey, u.Standard)
+	}
+
+	for standard, controls := range satisfies {
+		for _, c := range controls {
+			line := mdmd.yamlOffset + itemLine(mdmd.yaml, c.Key)
+			if !c.Status.Valid() {
+				l.report(file, line, RuleInvalidStatus, SeverityError, "%s:%s has unknown status %q (must be one of implemented, partial, planned, not-applicable)", standard, c.Key, c.Status)
+			}
+			if c.Status == NotApplicable && c.Justification == "" {
+				l.report(file, line, RuleMissingJustification, SeverityWarning, "%s:%s is not applicable but has no justification", standard, c.Key)
+			}
+		}
+	}
+}
+
+func (l *linter) lintDocument(file, content string) {
+	mdmd, err := parseMDMD(file, content)
+	if err != nil {
+		l.report(file, 1, RuleMalformedDocument, SeverityError, "document must be of the form: YAML\\n---\\nmarkdown content")
+		return
+	}
+
+	d := &Document{}
+	if !l.unmarshal(file, mdmd.yaml, mdmd.yamlOffset, d) {
+		return
+	}
+	l.lintSatisfies(file, mdmd, d.Satisfies)
+	if d.Name == "" {
+		l.report(file, mdmd.yamlOffset+1, RuleMissingName, SeverityError, "document is missing a name")
+	}
+	if d.Acronym == "" {
+		l.report(file, mdmd.yamlOffset+1, RuleMissingAcronym, SeverityError, "document is missing an acronym")
+		return
+	}
+
+	if first, ok := l.acronyms[d.Acronym]; ok {
+		l.report(file, mdmd.yamlOffset+keyLine(mdmd.yaml, "acronym"), RuleDuplicateAcronym, SeverityError, "acronym %s is already used by %s", d.Acronym, first)
+	} else {
+		l.acronyms[d.Acronym] = file
+	}
+}
+
+func (l *linter) lintProcedure(file, content string) {
+	mdmd, err := parseMDMD(file, content)
+	if err != nil {
+		l.report(file, 1, RuleMalformedDocument, SeverityError, "procedure must be of the form: YAML\\n---\\nmarkdown content")
+		return
+	}
+
+	p := &Procedure{}
+	if !l.unmarshal(file, mdmd.yaml, mdmd.yamlOffset, p) {
+		return
+	}
+	l.lintSatisfies(file, mdmd, p.Satisfies)
+	if p.Name == "" {
+		l.report(file, mdmd.yamlOffset+1, RuleMissingName, SeverityError, "procedure is missing a name")
+	}
+	if p.ID == "" {
+		l.report(file, mdmd.yamlOffset+1, RuleMissingID, SeverityError, "procedure is missing an id")
+	} else if first, ok := l.procedureIDs[p.ID]; ok {
+		l.report(file, mdmd.yamlOffset+keyLine(mdmd.yaml, "id"), RuleDuplicateProcedureID, SeverityError, "procedure ID %s is already used by %s", p.ID, first)
+	} else {
+		l.procedureIDs[p.ID] = file
+	}
+
+	if p.Freshness != "" {
+		if _, err := p.Freshness.Expires(time.Now()); err != nil {
+			l.report(file, mdmd.yamlOffset+keyLine(mdmd.yaml, "freshness"), RuleInvalidFreshness, SeverityError, "%v", err)
+		}
+	}
+
+	if p.Cron != "" {
+		if _, err := cron.Parse(p.Cron); err != nil {
+			l.report(file, mdmd.yamlOffset+keyLine(mdmd.yaml, "cron"), RuleInvalidCron, SeverityError, "invalid cron expression %q: %v", p.Cron, err)
+		}
+	}
+
+	if p.Timezone != "" {
+		if _, err := time.LoadLocation(p.Timezone); err != nil {
+			l.report(file, mdmd.yamlOffset+keyLine(mdmd.yaml, "timezone"), RuleInvalidTimezone, SeverityError, "unknown timezone %q", p.Timezone)
+		}
+	}
+
+	if !p.CatchUp.Valid() {
+		l.report(file, mdmd.yamlOffset+keyLine(mdmd.yaml, "catchUp"), RuleInvalidCatchUp, SeverityError, "unknown catchUp %q (must be one of none, latest, all)", p.CatchUp)
+	}
+
+	if p.TicketSystem != "" && !l.ticketSystems[p.TicketSystem] {
+		l.report(file, mdmd.yamlOffset+keyLine(mdmd.yaml, "ticketSystem"), RuleUnknownTicketSystem, SeverityError, "ticket system %s is not configured", p.TicketSystem)
+	}
+}
+
+var yamlLineRE = regexp.MustCompile(`line (\d+)`)
+
+// keyLine returns the 1-based line on which key is first declared, or 1 if absent.
+func keyLine(content, key string) int {
+	re := regexp.MustCompile(`^\s*` + regexp.QuoteMeta(key) + `\s*:`)
+	for i, line := range strings.Split(content, "\n") {
+		if re.MatchString(line) {
+			return i + 1
+		}
+	}
+	return 1
+}
+
+// itemLine returns the 1-based line of the first YAML list item with the given
+// value (or `control:`-style keyed value, or mapping key), or 1 if absent.
+func itemLine(content, value string) int {
+	lines := itemLines(content, value)
+	if len(lines) == 0 {
+		return 1
+	}
+	return lines[0]
+}
+
+// itemLines returns the 1-based lines of every YAML list item with the given value.
+func itemLines(content, value string) []int {
+	re := regexp.MustCompile(`^\s*-\s*([\w-]+\s*:\s*)?["']?` + regexp.QuoteMeta(value) + `["']?\s*(:.*)?$`)
+	var lines []int
+	for i, line := range strings.Split(content, "\n") {
+		if re.MatchString(line) {
+			lines = append(lines, i+1)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,156 @@
+package model
+
+import (
+	"testing"
+)
+
+func newTestLinter() *linter {
+	return &linter{
+		acronyms:      make(map[string]string),
+		procedureIDs:  make(map[string]string),
+		auditIDs:      make(map[string]string),
+		ticketSystems: map[string]bool{"github": true},
+	}
+}
+
+func TestLintDocument(t *testing.T) {
+	l := newTestLinter()
+	l.lintDocument("policies/a.md", "---\nname: Access Policy\nacronym: AP\n---\nbody")
+	l.lintDocument("policies/b.md", "---\nname: Another Policy\nacronym: AP\n---\nbody")
+	l.lintDocument("policies/c.md", "---\nname: [unterminated\n---\nbody")
+	l.lintDocument("policies/d.md", "no front matter")
+
+	expected := []struct {
+		file string
+		line int
+		rule string
+	}{
+		{"policies/b.md", 3, RuleDuplicateAcronym},
+		{"policies/c.md", 2, RuleMalformedYAML},
+		{"policies/d.md", 1, RuleMalformedDocument},
+	}
+	if len(l.diagnostics) != len(expected) {
+		t.Fatalf("expected %d diagnostics, got %d: %v", len(expected), len(l.diagnostics), l.diagnostics)
+	}
+	for i, e := range expected {
+		d := l.diagnostics[i]
+		if d.File != e.file || d.Line != e.line || d.Rule != e.rule {
+			t.Errorf("expected %s:%d %s, got %s:%d %s", e.file, e.line, e.rule, d.File, d.Line, d.Rule)
+		}
+	}
+}
+
+func TestLintProcedure(t *testing.T) {
+	l := newTestLinter()
+	l.lintProcedure("procedures/a.md", "---\nid: patch\nname: Patch\ncron: \"0 0 0 15 * *\"\n---\nbody")
+	l.lintProcedure("procedures/b.md", "---\nid: patch\nname: Patch Again\ncron: \"every tuesday\"\n---\nbody")
+	l.lintProcedure("procedures/c.md", "---\nname: Anonymous\n---\nbody")
+	l.lintProcedure("procedures/d.md", "---\nid: review\nname: Review\nticketSystem: github\n---\nbody")
+	l.lintProcedure("procedures/e.md", "---\nid: onboard\nname: Onboard\nticketSystem: jira\n---\nbody")
+	l.lintProcedure("procedures/f.md", "---\nid: backup\nname: Backup\ncatchUp: all\n---\nbody")
+	l.lintProcedure("procedures/g.md", "---\nid: restore\nname: Restore\ncatchUp: every\n---\nbody")
+	l.lintProcedure("procedures/h.md", "---\nid: standup\nname: Standup\ntimezone: America/New_York\n---\nbody")
+	l.lintProcedure("procedures/i.md", "---\nid: retro\nname: Retro\ntimezone: Mars/Olympus\n---\nbody")
+
+	rules := []string{RuleDuplicateProcedureID, RuleInvalidCron, RuleMissingID, RuleUnknownTicketSystem, RuleInvalidCatchUp, RuleInvalidTimezone}
+	if len(l.diagnostics) != len(rules) {
+		t.Fatalf("expected %d diagnostics, got %d", len(rules), len(l.diagnostics))
+	}
+	for i, rule := range rules {
+		if l.diagnostics[i].Rule != rule {
+			t.Errorf("expected rule %s, got %s", rule, l.diagnostics[i].Rule)
+		}
+	}
+	if l.diagnostics[1].Line != 4 {
+		t.Errorf("expected invalid cron on line 4, got %d", l.diagnostics[1].Line)
+	}
+	if l.diagnostics[3].Line != 4 {
+		t.Errorf("expected unknown ticket system on line 4, got %d", l.diagnostics[3].Line)
+	}
+}
+
+func TestLintSatisfies(t *testing.T) {
+	l := newTestLinter()
+	l.lintStandard("standards/TSC.yml", "name: TSC\nCC6.1:\n  name: Access\n")
+	l.lintDocument("policies/a.md", "---\nname: Access Policy\nacronym: AP\nsatisfies:\n  TSC:\n    - CC6.1\n    - CC6.9\n  ISO:\n    - A.1\n---\nbody")
+
+	if len(l.diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %d", len(l.diagnostics))
+	}
+	if d := l.diagnostics[0]; d.Rule != RuleUnknownStandard || d.Line != 8 {
+		t.Errorf("expected unknown standard on line 8, got %s on line %d", d.Rule, d.Line)
+	}
+	if d := l.diagnostics[1]; d.Rule != RuleUnknownControl || d.Line != 7 {
+		t.Errorf("expected unknown control on line 7, got %s on line %d", d.Rule, d.Line)
+	}
+}
+
+func TestLintMapping(t *testing.T) {
+	l := newTestLinter()
+	l.lintStandard("standards/TSC.yml", "name: TSC\nCC6.1:\n  name: Access\n")
+	l.lintStandard("standards/ISO.yml", "name: ISO27001\nA.9.2.1:\n  name: Registration\n")
+	l.lintMapping("mappings/soc2-iso.yml", "name: SOC2 to ISO\nmappings:\n  - TSC:CC6.1 <-> ISO27001:A.9.2.1\n  - TSC:CC6.9 -> ISO27001:A.9.2.1\n  - TSC CC6.1\n")
+
+	rules := []string{RuleUnknownControl, RuleMalformedMapping}
+	if len(l.diagnostics) != len(rules) {
+		t.Fatalf("expected %d diagnostics, got %d", len(rules), len(l.diagnostics))
+	}
+	for i, rule := range rules {
+		if d := l.diagnostics[i]; d.Rule != rule || d.Line != i+4 {
+			t.Errorf("expected %s on line %d, got %s on line %d", rule, i+4, d.Rule, d.Line)
+		}
+	}
+}
+
+func TestLintApplicability(t *testing.T) {
+	l := newTestLinter()
+	l.standards = []*Standard{{Name: "ISO27001", Controls: map[string]Control{"A.11.1.1": {}}}}
+	l.lintApplicability("applicability/iso.yml", "standard: ISO27001\ncontrols:\n  A.11.1.1:\n    applicable: false\n  A.99:\n    applicable: true\n")
+	l.lintApplicability("applicability/other.yml", "standard: CIS\ncontrols:\n  1.1:\n    applicable: true\n")
+
+	expected := []struct {
+		file string
+		line int
+		rule string
+	}{
+		{"applicability/iso.yml", 3, RuleMissingJustification},
+		{"applicability/iso.yml", 5, RuleUnknownControl},
+		{"applicability/other.yml", 1, RuleUnknownStandard},
+	}
+	if len(l.diagnostics) != len(expected) {
+		t.Fatalf("expected %d diagnostics, got %d: %v", len(expected), len(l.diagnostics), l.diagnostics)
+	}
+	for i, e := range expected {
+		d := l.diagnostics[i]
+		if d.File != e.file || d.Line != e.line || d.Rule != e.rule {
+			t.Errorf("expected %s:%d %s, got %s:%d %s", e.file, e.line, e.rule, d.File, d.Line, d.Rule)
+		}
+	}
+}
+
+func TestLintAudit(t *testing.T) {
+	l := newTestLinter()
+	l.standards = []*Standard{{Name: "TSC", Controls: map[string]Control{"CC6.2": {}}}}
+	l.lintAudit("audits/a.yml", "id: soc2\nname: SOC 2\nstandards:\n  - TSC\n  - ISO27001\nrequests:\n  - id: R1\n    controls:\n      - TSC:CC6.2\n      - TSC:CC9.9\n  - id: R1\n")
+	l.lintAudit("audits/b.yml", "id: soc2\nname: SOC 2 again\n")
+
+	expected := []struct {
+		file string
+		line int
+		rule string
+	}{
+		{"audits/a.yml", 5, RuleUnknownStandard},
+		{"audits/a.yml", 10, RuleUnknownControl},
+		{"audits/a.yml", 11, RuleDuplicateRequestID},
+		{"audits/b.yml", 1, RuleDuplicateAuditID},
+	}
+	if len(l.diagnostics) != len(expected) {
+		t.Fatalf("expected %d diagnostics, got %d: %v", len(expected), len(l.diagnostics), l.diagnostics)
+	}
+	for i, e := range expected {
+		d := l.diagnostics[i]
+		if d.File != e.file || d.Line != e.line || d.Rule != e.rule {
+			t.Errorf("expected %s:%d %s, got %s:%d %s", e.file, e.line, e.rule, d.File, d.Line, d.Rule)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mapping declares equivalences between the controls of different standards,
+// e.g. "TSC:CC6.1 <-> ISO27001:A.9.2.1". A "->" mapping applies in one direction only.
+type Mapping struct {
+	Name     string   `yaml:"name"`
+	Mappings []string `yaml:"mappings"`
+	FullPath string
+}
+
+// ControlMapping propagates satisfaction of one control to another.
+type ControlMapping struct {
+	From ControlKey
+	To   ControlKey
+}
+
+// ControlMappings parses all declared equivalences.
+func (m *Mapping) ControlMappings() ([]ControlMapping, error) {
+	var mappings []ControlMapping
+	for _, s := range m.Mappings {
+		parsed, err := ParseMapping(s)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, parsed...)
+	}
+	return mappings, nil
+}
+
+// ParseMapping parses a single "STANDARD:CONTROL <-> STANDARD:CONTROL" or
+// "STANDARD:CONTROL -> STANDARD:CONTROL" declaration.
+func ParseMapping(s string) ([]ControlMapping, error) {
+	operator := "<->"
+	if !strings.Contains(s, operator) {
+		operator = "->"
+	}
+
+	sides := strings.Split(s, operator)
+	if len(sides) != 2 {
+		return nil, fmt.Errorf("malformed mapping %q, must be of the form: STANDARD:CONTROL <-> STANDARD:CONTROL", s)
+	}
+	from, err := parseControlKey(sides[0])
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseControlKey(sides[1])
+	if err != nil {
+		return nil, err
+	}
+
+	mappings := []ControlMapping{{From: from, To: to}}
+	if operator == "<->" {
+		mappings = append(mappings, ControlMapping{From: to, To: from})
+	}
+	return mappings, nil
+}
+
+func parseControlKey(s string) (ControlKey, error) {
+	tokens := strings.SplitN(strings.TrimSpace(s), ":", 2)
+	if len(tokens) != 2 || strings.TrimSpace(tokens[0]) == "" || strings.TrimSpace(tokens[1]) == "" {
+		return ControlKey{}, fmt.Errorf("malformed control reference %q, must be of the form: STANDARD:CONTROL", strings.TrimSpace(s))
+	}
+	return ControlKey{Standard: strings.TrimSpace(tokens[0]), Control: strings.TrimSpace(tokens[1])}, nil
+}
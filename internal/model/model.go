@@ -7,11 +7,13 @@ type Data struct {
 	Procedures []*Procedure
 	Tickets    []*Ticket
 	Audits     []*Audit
+	Mappings   []*Mapping
+
+	Applicability []*Applicability
+	Evidence      []*Evidence
 }
 
 type Revision struct {
 	Date    string `yaml:"date"`
 	Comment string `yaml:"comment"`
 }
-
-type Satisfaction map[string][]string
@@ -20,11 +20,11 @@ func TestMarshal(t *testing.T) {
 		},
 		Procedures: []*Procedure{
 			&Procedure{
-				Code: "pro1",
+				ID: "pro1",
 			},
 		},
-		Policies: []*Policy{
-			&Policy{
+		Policies: []*Document{
+			&Document{
 				Name: "pol1",
 			},
 		},
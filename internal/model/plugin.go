@@ -3,6 +3,7 @@ package model
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/strongdm/comply/internal/config"
@@ -10,7 +11,7 @@ import (
 
 var tsPluginsMu sync.Mutex
 var tsPlugins = make(map[TicketSystem]TicketPlugin)
-var tsConfigureOnce sync.Once
+var tsConfigured = make(map[TicketSystem]bool)
 
 // TicketSystem is the type of ticket database.
 type TicketSystem string
@@ -22,6 +23,12 @@ const (
 	GitHub = TicketSystem(config.GitHub)
 	// GitLab from GitLab.
 	GitLab = TicketSystem(config.GitLab)
+	// AzureDevOps Boards from Microsoft.
+	AzureDevOps = TicketSystem(config.AzureDevOps)
+	// Linear from Linear.
+	Linear = TicketSystem(config.Linear)
+	// Local markdown files in the tickets folder.
+	Local = TicketSystem(config.Local)
 	// NoTickets indicates no ticketing system integration.
 	NoTickets = TicketSystem(config.NoTickets)
 )
@@ -39,6 +46,8 @@ type TicketPlugin interface {
 	FindOpen() ([]*Ticket, error)
 	FindByTag(name, value string) ([]*Ticket, error)
 	FindByTagName(name string) ([]*Ticket, error)
+	// FindByTagNameSince finds tickets with the given tag updated at or after since.
+	FindByTagNameSince(name string, since time.Time) ([]*Ticket, error)
 	Create(ticket *Ticket, labels []string) error
 	Configure(map[string]interface{}) error
 	Prompts() map[string]string
@@ -61,43 +70,61 @@ func GetPlugin(ts TicketSystem) TicketPlugin {
 		panic("Unknown ticket system: " + ts)
 	}
 
-	if config.Exists() {
-		tsConfigureOnce.Do(func() {
-			ticketsMap := config.Config().Tickets
-			hasTickets := true
+	// each ticket system is configured on first use
+	if config.Exists() && !tsConfigured[ts] {
+		tsConfigured[ts] = true
 
-			cfg, ok := ticketsMap[string(ts)]
+		cfg, ok := config.Config().Tickets[string(ts)]
+		if ok {
+			cfgTyped, ok := cfg.(map[interface{}]interface{})
 			if !ok {
-				hasTickets = false
+				spew.Dump(cfg)
+				panic(fmt.Sprintf("malformatted ticket configuration block `%s` in project YAML", string(ts)))
 			}
 
-			if hasTickets {
-				cfgTyped, ok := cfg.(map[interface{}]interface{})
+			cfgStringed := make(map[string]interface{})
+			for k, v := range cfgTyped {
+				kS, ok := k.(string)
 				if !ok {
-					spew.Dump(cfg)
-					panic(fmt.Sprintf("malformatted ticket configuration block `%s` in project YAML", string(ts)))
-				}
-
-				cfgStringed := make(map[string]interface{})
-				for k, v := range cfgTyped {
-					kS, ok := k.(string)
-					if !ok {
-						spew.Dump(cfgStringed)
-						panic(fmt.Sprintf("malformatted key in configuration block `%s` in project YAML", string(ts)))
-					}
-					cfgStringed[kS] = v
-				}
-				err := tp.Configure(cfgStringed)
-				if err != nil {
-					panic(fmt.Sprintf("Configuration error `%s` in project YAML", err))
+					spew.Dump(cfgStringed)
+					panic(fmt.Sprintf("malformatted key in configuration block `%s` in project YAML", string(ts)))
 				}
+				cfgStringed[kS] = v
+			}
+			err := tp.Configure(cfgStringed)
+			if err != nil {
+				panic(fmt.Sprintf("Configuration error `%s` in project YAML", err))
 			}
-		})
+		}
 	}
 
 	return tp
 }
 
+// DefaultTicketSystem is the project-wide ticket system.
+func DefaultTicketSystem() (TicketSystem, error) {
+	ts, err := config.Config().TicketSystem()
+	return TicketSystem(ts), err
+}
+
+// ProcedureTicketSystem is the ticket system a procedure targets, defaulting
+// to the project-wide ticket system.
+func ProcedureTicketSystem(p *Procedure) (TicketSystem, error) {
+	if p.TicketSystem == "" {
+		return DefaultTicketSystem()
+	}
+	systems, err := config.Config().TicketSystems()
+	if err != nil {
+		return NoTickets, err
+	}
+	for _, ts := range systems {
+		if ts == p.TicketSystem {
+			return TicketSystem(ts), nil
+		}
+	}
+	return NoTickets, fmt.Errorf("procedure %s targets ticket system %s, which is not configured", p.ID, p.TicketSystem)
+}
+
 // Register ticketing system plugin.
 func Register(ts TicketSystem, plugin TicketPlugin) {
 	tsPluginsMu.Lock()
@@ -124,6 +151,9 @@ func (*noopTicketSystem) FindByTag(name, value string) ([]*Ticket, error) {
 func (*noopTicketSystem) FindByTagName(name string) ([]*Ticket, error) {
 	return []*Ticket{}, nil
 }
+func (*noopTicketSystem) FindByTagNameSince(name string, since time.Time) ([]*Ticket, error) {
+	return []*Ticket{}, nil
+}
 func (*noopTicketSystem) Create(ticket *Ticket, labels []string) error {
 	return nil
 }
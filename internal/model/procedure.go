@@ -1,11 +1,31 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"github.com/robfig/cron"
+	"github.com/strongdm/comply/internal/config"
+)
 
 type Procedure struct {
 	Name string `yaml:"name"`
 	ID   string `yaml:"id"`
 	Cron string `yaml:"cron"`
+	// Freshness optionally requires evidence of the procedure to be collected periodically
+	Freshness Freshness `yaml:"freshness"`
+	// TicketSystem optionally overrides the project-wide ticket system
+	TicketSystem string `yaml:"ticketSystem"`
+	// Assignee, Labels, Priority, DueInDays and Component optionally populate
+	// the procedure's tickets
+	Assignee  string   `yaml:"assignee"`
+	Labels    []string `yaml:"labels"`
+	Priority  string   `yaml:"priority"`
+	DueInDays int      `yaml:"dueInDays"`
+	Component string   `yaml:"component"`
+	// CatchUp is how occurrences missed by the scheduler are ticketed
+	CatchUp CatchUp `yaml:"catchUp"`
+	// Timezone optionally overrides the project timezone Cron is evaluated in
+	Timezone string `yaml:"timezone"`
 
 	Revisions      []Revision   `yaml:"majorRevisions"`
 	Satisfies      Satisfaction `yaml:"satisfies"`
@@ -14,3 +34,84 @@ type Procedure struct {
 	ModifiedAt     time.Time
 	Body           string
 }
+
+// CatchUp is how the scheduler tickets occurrences of a procedure's schedule
+// that came due while it was not running.
+type CatchUp string
+
+const (
+	// CatchUpNone skips occurrences not ticketed within a day of coming due.
+	CatchUpNone = CatchUp("none")
+	// CatchUpLatest tickets only the most recent occurrence, the default.
+	CatchUpLatest = CatchUp("latest")
+	// CatchUpAll tickets every occurrence since the last one ticketed.
+	CatchUpAll = CatchUp("all")
+)
+
+// Valid indicates a recognized catch-up policy; empty defaults to latest.
+func (c CatchUp) Valid() bool {
+	switch c {
+	case "", CatchUpNone, CatchUpLatest, CatchUpAll:
+		return true
+	}
+	return false
+}
+
+// Location is the time zone Cron is evaluated in: the procedure's timezone,
+// else the project's, else UTC.
+func (p *Procedure) Location() (*time.Location, error) {
+	name := p.Timezone
+	if name == "" && config.Exists() {
+		name = config.Config().Timezone
+	}
+	return time.LoadLocation(name)
+}
+
+// NextRun is when Cron next fires after now, in the procedure's location, or
+// zero if the procedure is not scheduled.
+func (p *Procedure) NextRun(now time.Time) (time.Time, error) {
+	if p.Cron == "" {
+		return time.Time{}, nil
+	}
+	loc, err := p.Location()
+	if err != nil {
+		return time.Time{}, err
+	}
+	schedule, err := cron.Parse(p.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.Next(now.In(loc)), nil
+}
+
+// Ticket creates a ticket tracking an execution of the procedure scheduled
+// for the given time, due DueInDays later, to be labeled with TicketLabels.
+func (p *Procedure) Ticket(scheduledFor time.Time) *Ticket {
+	t := &Ticket{
+		Name: p.Name,
+		Body: WithTrailers(p.Body,
+			Trailer{Key: "Procedure-ID", Value: p.ID},
+			Trailer{Key: "Scheduled-For", Value: scheduledFor.UTC().Format(time.RFC3339)},
+		),
+		Assignee:  p.Assignee,
+		Priority:  p.Priority,
+		Component: p.Component,
+	}
+	if p.DueInDays > 0 {
+		due := scheduledFor.AddDate(0, 0, p.DueInDays)
+		t.DueDate = &due
+	}
+	return t
+}
+
+// TicketLabels are "comply", "comply-procedure", the procedure label, e.g.
+// "procedure:patch", and any labels the procedure adds.
+func (p *Procedure) TicketLabels() []string {
+	labels := []string{LabelComply, LabelProcedure, TrailerLabel("Procedure-ID", p.ID)}
+	for _, l := range p.Labels {
+		if l != LabelComply && l != LabelProcedure {
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
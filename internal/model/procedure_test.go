@@ -0,0 +1,31 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProcedureTicket(t *testing.T) {
+	p := &Procedure{ID: "patch", Name: "Apply OS patches", Body: "Patch all hosts", Assignee: "jdoe", Priority: "high", DueInDays: 7, Labels: []string{"ops", "comply"}}
+
+	scheduled := time.Date(2018, 5, 15, 0, 0, 0, 0, time.UTC)
+	ticket := p.Ticket(scheduled)
+	if ticket.ProcedureID() != "patch" || ticket.Assignee != "jdoe" || ticket.Priority != "high" {
+		t.Errorf("unexpected ticket: %+v", ticket)
+	}
+	if ticket.DueDate == nil || !ticket.DueDate.Equal(time.Date(2018, 5, 22, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected due date: %v", ticket.DueDate)
+	}
+	if ticket.ScheduledFor() == nil || !ticket.ScheduledFor().Equal(scheduled) {
+		t.Errorf("unexpected scheduled time: %v", ticket.ScheduledFor())
+	}
+	if labels := p.TicketLabels(); !reflect.DeepEqual(labels, []string{"comply", "comply-procedure", "procedure:patch", "ops"}) {
+		t.Errorf("unexpected labels: %v", labels)
+	}
+
+	p.DueInDays = 0
+	if ticket = p.Ticket(time.Now()); ticket.DueDate != nil {
+		t.Errorf("expected no due date: %v", ticket.DueDate)
+	}
+}
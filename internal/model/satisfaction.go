@@ -0,0 +1,159 @@
+package model
+
+import (
+	"errors"
+	"strings"
+)
+
+// Satisfaction maps standard names to the controls a document addresses.
+type Satisfaction map[string][]SatisfiedControl
+
+// ControlStatus describes how completely a document implements a control.
+type ControlStatus string
+
+const (
+	// Implemented controls are fully satisfied.
+	Implemented = ControlStatus("implemented")
+	// Partial controls are partially satisfied.
+	Partial = ControlStatus("partial")
+	// Planned controls are not yet satisfied.
+	Planned = ControlStatus("planned")
+	// NotApplicable controls are excluded from coverage, with a justification.
+	NotApplicable = ControlStatus("not-applicable")
+)
+
+// Valid indicates a recognized status.
+func (s ControlStatus) Valid() bool {
+	switch s {
+	case Implemented, Partial, Planned, NotApplicable:
+		return true
+	}
+	return false
+}
+
+// rank orders statuses from least to most complete.
+func (s ControlStatus) rank() int {
+	switch s {
+	case Implemented:
+		return 4
+	case NotApplicable:
+		return 3
+	case Partial:
+		return 2
+	}
+	return 1
+}
+
+// SatisfiedControl is an entry in a `satisfies` block. A bare control key is
+// implemented; the status may otherwise be declared inline or in full:
+//
+//	satisfies:
+//	  TSC:
+//	    - CC6.1
+//	    - CC6.2: partial
+//	    - control: CC9.2
+//	      status: not-applicable
+//	      justification: No third-party vendors process customer data
+type SatisfiedControl struct {
+	Key           string
+	Status        ControlStatus
+	Justification string
+}
+
+// UnmarshalYAML accepts the bare, inline and full forms of a satisfied control.
+func (c *SatisfiedControl) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var key string
+	if err := unmarshal(&key); err == nil {
+		c.Key = key
+		c.Status = Implemented
+		return nil
+	}
+
+	var full struct {
+		Control       string        `yaml:"control"`
+		Status        ControlStatus `yaml:"status"`
+		Justification string        `yaml:"justification"`
+	}
+	if err := unmarshal(&full); err == nil && full.Control != "" {
+		c.Key = full.Control
+		c.Status = full.Status
+		c.Justification = full.Justification
+		if c.Status == "" {
+			c.Status = Implemented
+		}
+		return nil
+	}
+
+	var inline map[string]ControlStatus
+	if err := unmarshal(&inline); err == nil && len(inline) == 1 {
+		for key, status := range inline {
+			c.Key = key
+			c.Status = status
+		}
+		return nil
+	}
+
+	return errors.New("satisfied controls must be a control key, `KEY: status`, or a mapping with control, status and justification")
+}
+
+func (c SatisfiedControl) String() string {
+	if c.Status == Implemented || c.Status == "" {
+		return c.Key
+	}
+	return c.Key + " (" + strings.Replace(string(c.Status), "-", " ", -1) + ")"
+}
+
+// EffectiveStatus is the most complete status declared by any satisfier, or
+// the empty string when a control has no satisfiers.
+func EffectiveStatus(satisfiers []*Satisfier) ControlStatus {
+	var status ControlStatus
+	for _, s := range satisfiers {
+		if status == "" || s.Status.rank() > status.rank() {
+			status = s.Status
+		}
+	}
+	return status
+}
+
+// Coverage summarizes the status of every control in a standard.
+type Coverage struct {
+	Total         int
+	Implemented   int
+	Partial       int
+	Planned       int
+	NotApplicable int
+	// Inherited counts controls satisfied only through mappings
+	Inherited int
+}
+
+// Percent weighs partial controls as half implemented and excludes
+// not-applicable controls.
+func (c Coverage) Percent() int {
+	applicable := c.Total - c.NotApplicable
+	if applicable <= 0 {
+		return 100
+	}
+	return int((float64(c.Implemented) + float64(c.Partial)/2) * 100 / float64(applicable))
+}
+
+// StandardCoverage computes the coverage of a standard from ControlsSatisfied.
+func StandardCoverage(standard *Standard, satisfied map[ControlKey][]*Satisfier) Coverage {
+	c := Coverage{Total: len(standard.Controls)}
+	for key := range standard.Controls {
+		satisfiers := satisfied[ControlKey{Standard: standard.Name, Control: key}]
+		switch EffectiveStatus(satisfiers) {
+		case Implemented:
+			c.Implemented++
+		case Partial:
+			c.Partial++
+		case Planned:
+			c.Planned++
+		case NotApplicable:
+			c.NotApplicable++
+		}
+		if len(satisfiers) > 0 && !DirectlySatisfied(satisfiers) {
+			c.Inherited++
+		}
+	}
+	return c
+}
@@ -1,9 +1,13 @@
 package model
 
+import "sort"
+
 type Control struct {
 	Family      string `yaml:"family"`
 	Name        string `yaml:"name"`
 	Description string `yaml:"description"`
+	// Freshness optionally requires evidence to be collected periodically
+	Freshness Freshness `yaml:"freshness"`
 }
 
 type Standard struct {
@@ -11,39 +15,161 @@ type Standard struct {
 	Controls map[string]Control `yaml:",inline"`
 }
 
-// ControlsSatisfied determines the unique controls currently satisfied by all Narratives, Policies, and Procedures
-func ControlsSatisfied(data *Data) map[string][]string {
-	satisfied := make(map[string][]string)
+// ControlKey identifies a control within a named standard.
+type ControlKey struct {
+	Standard string
+	Control  string
+}
+
+func (k ControlKey) String() string {
+	return k.Standard + ":" + k.Control
+}
+
+// Satisfier records a document satisfying a control.
+type Satisfier struct {
+	OutputFilename string
+	Status         ControlStatus
+	Justification  string
+	// Via is the directly satisfied control when satisfaction is inherited through a mapping
+	Via *ControlKey
+}
 
-	appendSatisfaction := func(in map[string][]string, k string, v string) []string {
-		s, ok := in[k]
-		if !ok {
-			s = make([]string, 0)
+// Inherited indicates satisfaction was propagated through a mapping.
+func (s *Satisfier) Inherited() bool {
+	return s.Via != nil
+}
+
+// ControlsSatisfied determines the unique controls currently satisfied by all Narratives, Policies, and Procedures,
+// including controls inherited through a single hop of the declared Mappings
+func ControlsSatisfied(data *Data) map[ControlKey][]*Satisfier {
+	satisfied := make(map[ControlKey][]*Satisfier)
+
+	appendSatisfaction := func(satisfies Satisfaction, outputFilename string) {
+		for standard, controlKeys := range satisfies {
+			for _, c := range controlKeys {
+				k := ControlKey{Standard: standard, Control: c.Key}
+				satisfied[k] = append(satisfied[k], &Satisfier{
+					OutputFilename: outputFilename,
+					Status:         c.Status,
+					Justification:  c.Justification,
+				})
+			}
 		}
-		s = append(s, v)
-		return s
 	}
 
 	for _, n := range data.Narratives {
-		for _, controlKeys := range n.Satisfies {
-			for _, key := range controlKeys {
-				satisfied[key] = appendSatisfaction(satisfied, key, n.OutputFilename)
-			}
-		}
+		appendSatisfaction(n.Satisfies, n.OutputFilename)
 	}
 	for _, n := range data.Policies {
-		for _, controlKeys := range n.Satisfies {
-			for _, key := range controlKeys {
-				satisfied[key] = appendSatisfaction(satisfied, key, n.OutputFilename)
+		appendSatisfaction(n.Satisfies, n.OutputFilename)
+	}
+	for _, n := range data.Procedures {
+		appendSatisfaction(n.Satisfies, n.OutputFilename)
+	}
+
+	// mappings are not transitive: only direct satisfaction is propagated
+	inherited := make(map[ControlKey][]*Satisfier)
+	for _, m := range data.Mappings {
+		controlMappings, err := m.ControlMappings()
+		if err != nil {
+			// reported by Lint
+			continue
+		}
+		for _, cm := range controlMappings {
+			for _, s := range satisfied[cm.From] {
+				if hasSatisfier(satisfied[cm.To], s.OutputFilename) || hasSatisfier(inherited[cm.To], s.OutputFilename) {
+					continue
+				}
+				via := cm.From
+				inherited[cm.To] = append(inherited[cm.To], &Satisfier{
+					OutputFilename: s.OutputFilename,
+					Status:         s.Status,
+					Justification:  s.Justification,
+					Via:            &via,
+				})
 			}
 		}
 	}
+	for k, satisfiers := range inherited {
+		satisfied[k] = append(satisfied[k], satisfiers...)
+	}
+
+	return satisfied
+}
+
+// DirectlySatisfied indicates at least one satisfier was not inherited through a mapping.
+func DirectlySatisfied(satisfiers []*Satisfier) bool {
+	for _, s := range satisfiers {
+		if !s.Inherited() {
+			return true
+		}
+	}
+	return false
+}
+
+func hasSatisfier(satisfiers []*Satisfier, outputFilename string) bool {
+	for _, s := range satisfiers {
+		if s.OutputFilename == outputFilename {
+			return true
+		}
+	}
+	return false
+}
+
+// UnknownControl is a `satisfies` reference to a standard or control that is
+// not declared by any loaded standard.
+type UnknownControl struct {
+	// Document is the full path of the referencing narrative, policy or procedure.
+	Document   string
+	Standard   string
+	ControlKey string
+	// UnknownStandard is set when no loaded standard has the referenced name.
+	UnknownStandard bool
+}
+
+// UnknownControls cross-checks the Satisfies block of every Narrative, Policy and Procedure against the loaded standards
+func UnknownControls(data *Data) []*UnknownControl {
+	var unknown []*UnknownControl
+	for _, n := range data.Narratives {
+		unknown = append(unknown, unknownControls(data.Standards, n.FullPath, n.Satisfies)...)
+	}
+	for _, n := range data.Policies {
+		unknown = append(unknown, unknownControls(data.Standards, n.FullPath, n.Satisfies)...)
+	}
 	for _, n := range data.Procedures {
-		for _, controlKeys := range n.Satisfies {
-			for _, key := range controlKeys {
-				satisfied[key] = appendSatisfaction(satisfied, key, n.OutputFilename)
+		unknown = append(unknown, unknownControls(data.Standards, n.FullPath, n.Satisfies)...)
+	}
+	return unknown
+}
+
+func unknownControls(standards []*Standard, document string, satisfies Satisfaction) []*UnknownControl {
+	byName := make(map[string]*Standard)
+	for _, s := range standards {
+		byName[s.Name] = s
+	}
+
+	var names []string
+	for name := range satisfies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var unknown []*UnknownControl
+	for _, name := range names {
+		standard, ok := byName[name]
+		for _, c := range satisfies[name] {
+			if ok {
+				if _, declared := standard.Controls[c.Key]; declared {
+					continue
+				}
 			}
+			unknown = append(unknown, &UnknownControl{
+				Document:        document,
+				Standard:        name,
+				ControlKey:      c.Key,
+				UnknownStandard: !ok,
+			})
 		}
 	}
-	return satisfied
+	return unknown
 }
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestControlsSatisfied(t *testing.T) {
+	d := &Data{
+		Policies: []*Document{
+			{OutputFilename: "AP.pdf", Satisfies: Satisfaction{"TSC": {{Key: "CC6.1", Status: Implemented}}}},
+		},
+		Procedures: []*Procedure{
+			{OutputFilename: "onboard.pdf", Satisfies: Satisfaction{"ISO27001": {{Key: "1.1", Status: Partial}}}},
+		},
+		Mappings: []*Mapping{
+			{Mappings: []string{"TSC:CC6.1 <-> ISO27001:A.9.2.1", "CIS:1.1 -> TSC:CC6.1"}},
+		},
+	}
+
+	satisfied := ControlsSatisfied(d)
+
+	if _, ok := satisfied[ControlKey{Standard: "CIS", Control: "1.1"}]; ok {
+		t.Error("ISO27001:1.1 must not satisfy CIS:1.1")
+	}
+
+	direct := satisfied[ControlKey{Standard: "TSC", Control: "CC6.1"}]
+	if len(direct) != 1 || direct[0].Inherited() {
+		t.Errorf("expected TSC:CC6.1 to be directly satisfied, got %v", direct)
+	}
+
+	inherited := satisfied[ControlKey{Standard: "ISO27001", Control: "A.9.2.1"}]
+	if len(inherited) != 1 || !inherited[0].Inherited() || inherited[0].Via.String() != "TSC:CC6.1" {
+		t.Errorf("expected ISO27001:A.9.2.1 to be satisfied via TSC:CC6.1, got %v", inherited)
+	}
+
+	if status := EffectiveStatus(satisfied[ControlKey{Standard: "ISO27001", Control: "1.1"}]); status != Partial {
+		t.Errorf("expected ISO27001:1.1 to be partial, got %s", status)
+	}
+}
+
+func TestSatisfiedControlYAML(t *testing.T) {
+	var s Satisfaction
+	err := yaml.Unmarshal([]byte(`
+TSC:
+  - CC6.1
+  - CC6.2: partial
+  - control: CC9.2
+    status: not-applicable
+    justification: No vendors
+`), &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []SatisfiedControl{
+		{Key: "CC6.1", Status: Implemented},
+		{Key: "CC6.2", Status: Partial},
+		{Key: "CC9.2", Status: NotApplicable, Justification: "No vendors"},
+	}
+	if len(s["TSC"]) != len(expected) {
+		t.Fatalf("expected %d controls, got %d", len(expected), len(s["TSC"]))
+	}
+	for i, e := range expected {
+		if s["TSC"][i] != e {
+			t.Errorf("expected %+v, got %+v", e, s["TSC"][i])
+		}
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	c := Coverage{Total: 10, Implemented: 5, Partial: 2, Planned: 1, NotApplicable: 2}
+	if c.Percent() != 75 {
+		t.Errorf("expected 75%% coverage, got %d%%", c.Percent())
+	}
+}
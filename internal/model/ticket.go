@@ -1,6 +1,9 @@
 package model
 
 import (
+	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 )
@@ -21,30 +24,158 @@ type Ticket struct {
 	ClosedAt   *time.Time
 	CreatedAt  *time.Time
 	UpdatedAt  *time.Time
+	// Source is the ticket system the ticket was synced from, empty for
+	// tickets cached before multiple ticket systems were supported
+	Source TicketSystem
+	// Assignee, Priority, DueDate and Component are set when creating
+	// tickets, where the ticket system supports them
+	Assignee  string
+	Priority  string
+	DueDate   *time.Time
+	Component string
+}
+
+// Trailer is a "Key: Value" line in the metadata block ending the body of
+// tickets comply creates.
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+// complyVersion is recorded in ticket trailers, if set.
+var complyVersion string
+
+// SetVersion records the comply version during initialization.
+func SetVersion(version string) {
+	complyVersion = version
+}
+
+// WithTrailers appends a metadata block, including the comply version, to a
+// ticket body:
+//
+//	body
+//
+//
+//	---
+//	Procedure-ID: patch
+//	Comply-Version: 1.4.0
+func WithTrailers(body string, trailers ...Trailer) string {
+	if complyVersion != "" {
+		trailers = append(trailers, Trailer{Key: "Comply-Version", Value: complyVersion})
+	}
+	lines := make([]string, len(trailers))
+	for i, tr := range trailers {
+		lines[i] = tr.Key + ": " + tr.Value
+	}
+	return fmt.Sprintf("%s\n\n\n---\n%s", body, strings.Join(lines, "\n"))
 }
 
 func (t *Ticket) ProcedureID() string {
-	md := t.metadata()
-	if v, ok := md["Procedure-ID"]; ok {
+	return t.metadata("Procedure-ID")
+}
+
+// AuditID is the audit an evidence-request ticket belongs to.
+func (t *Ticket) AuditID() string {
+	return t.metadata("Audit-ID")
+}
+
+// RequestID is the audit request an evidence-request ticket fulfills.
+func (t *Ticket) RequestID() string {
+	return t.metadata("Request-ID")
+}
+
+// ScheduledFor is when the procedure execution a ticket tracks was scheduled.
+func (t *Ticket) ScheduledFor() *time.Time {
+	scheduled, err := time.Parse(time.RFC3339, t.metadata("Scheduled-For"))
+	if err != nil {
+		return nil
+	}
+	return &scheduled
+}
+
+// OccurrenceKey identifies an execution of a procedure scheduled for the given
+// time, e.g. "patch@2018-05-15T00:00:00Z".
+func OccurrenceKey(procedureID string, scheduledFor time.Time) string {
+	return procedureID + "@" + scheduledFor.UTC().Format(time.RFC3339)
+}
+
+// Occurrence is the OccurrenceKey of the procedure execution the ticket
+// tracks, or empty for tickets created before occurrences were recorded.
+func (t *Ticket) Occurrence() string {
+	scheduled := t.ScheduledFor()
+	if t.ProcedureID() == "" || scheduled == nil {
+		return ""
+	}
+	return OccurrenceKey(t.ProcedureID(), *scheduled)
+}
+
+// ComplyVersion is the version of comply that created the ticket.
+func (t *Ticket) ComplyVersion() string {
+	return t.metadata("Comply-Version")
+}
+
+// EvidenceFor is the subject of an evidence collection ticket, see FreshnessCheck.Subject.
+func (t *Ticket) EvidenceFor() string {
+	if id := t.metadata("Evidence-Procedure"); id != "" {
+		return "procedure " + id
+	}
+	standard, control := t.metadata("Evidence-Standard"), t.metadata("Evidence-Control")
+	if standard == "" || control == "" {
+		return ""
+	}
+	return ControlKey{Standard: standard, Control: control}.String()
+}
+
+// metadata is the value of a trailer, falling back to a label named after the
+// key, e.g. "procedure:patch" for Procedure-ID, for tickets whose body was edited.
+func (t *Ticket) metadata(key string) string {
+	if v, ok := t.trailers()[key]; ok {
 		return v
 	}
-	return ""
-}
-
-func (t *Ticket) metadata() map[string]string {
-	md := make(map[string]string)
-	lines := strings.Split(t.Body, "\n")
-	for _, line := range lines {
-		// TODO: transition to RFC822 parsing
-		if strings.Contains(line, ":") {
-			tokens := strings.Split(line, ":")
-			if len(tokens) != 2 {
-				continue
-			}
-			md[strings.TrimSpace(tokens[0])] = strings.TrimSpace(tokens[1])
+	prefix := TrailerLabel(key, "")
+	var labels []string
+	for name := range t.Attributes {
+		if strings.HasPrefix(name, prefix) && t.Bool(name) {
+			labels = append(labels, name)
+		}
+	}
+	if len(labels) == 0 {
+		return ""
+	}
+	sort.Strings(labels)
+	return strings.TrimPrefix(labels[0], prefix)
+}
+
+// TrailerLabel is the label recording a trailer value: the lowercase key, less
+// any "-id" suffix, followed by ":" and the value.
+func TrailerLabel(key, value string) string {
+	return strings.TrimSuffix(strings.ToLower(key), "-id") + ":" + value
+}
+
+var trailerKeyRE = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*$`)
+
+// trailers parses the "Key: Value" lines following the last "---" line of the
+// body. Values may contain colons; a final section containing anything other
+// than trailers is body content, and yields none.
+func (t *Ticket) trailers() map[string]string {
+	trailers := make(map[string]string)
+	body := "\n" + strings.TrimSpace(strings.Replace(t.Body, "\r\n", "\n", -1))
+	i := strings.LastIndex(body, "\n---\n")
+	if i == -1 {
+		return trailers
+	}
+	for _, line := range strings.Split(body[i+len("\n---\n"):], "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		tokens := strings.SplitN(line, ":", 2)
+		if len(tokens) != 2 || !trailerKeyRE.MatchString(tokens[0]) {
+			return make(map[string]string)
 		}
+		trailers[tokens[0]] = strings.TrimSpace(tokens[1])
 	}
-	return md
+	return trailers
 }
 
 func (t *Ticket) SetBool(name string) {
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestTrailers(t *testing.T) {
+	SetVersion("1.4.0")
+	defer SetVersion("")
+
+	body := WithTrailers("See https://example.com/runbook at 10:30.\n\n---\nNot: metadata", Trailer{Key: "Procedure-ID", Value: "patch"}, Trailer{Key: "Scheduled-For", Value: "2018-05-15T00:00:00Z"})
+	ticket := &Ticket{Body: body}
+	if ticket.ProcedureID() != "patch" || ticket.ComplyVersion() != "1.4.0" {
+		t.Errorf("unexpected trailers in %q", body)
+	}
+	if ticket.ScheduledFor() == nil || ticket.ScheduledFor().Format(DateFormat) != "2018-05-15" {
+		t.Errorf("expected value containing colons to be preserved, got %v", ticket.ScheduledFor())
+	}
+	if ticket.metadata("Not") != "" {
+		t.Error("expected only the final section to be parsed")
+	}
+
+	cases := []struct {
+		body     string
+		expected string
+	}{
+		{"Procedure-ID: patch", ""},
+		{"steps\r\n\r\n\r\n---\r\nProcedure-ID: patch\r\n", "patch"},
+		{"steps\n\n---\nProcedure-ID: patch\nThanks, done: see https://example.com", ""},
+		{"---\nProcedure-ID: patch", "patch"},
+	}
+	for _, c := range cases {
+		if id := (&Ticket{Body: c.body}).ProcedureID(); id != c.expected {
+			t.Errorf("expected procedure %q in %q, got %q", c.expected, c.body, id)
+		}
+	}
+}
+
+func TestTrailerLabelFallback(t *testing.T) {
+	ticket := &Ticket{Body: "trailers removed while editing", Attributes: make(map[string]interface{})}
+	ticket.SetBool("comply")
+	ticket.SetBool(TrailerLabel("Procedure-ID", "patch"))
+	ticket.SetBool(TrailerLabel("Evidence-Standard", "TSC"))
+	ticket.SetBool(TrailerLabel("Evidence-Control", "CC6.1"))
+
+	if ticket.ProcedureID() != "patch" {
+		t.Errorf("expected procedure from label, got %q", ticket.ProcedureID())
+	}
+	if ticket.EvidenceFor() != "TSC:CC6.1" {
+		t.Errorf("expected evidence subject from labels, got %q", ticket.EvidenceFor())
+	}
+}
@@ -36,6 +36,92 @@ func Procedures() ([]File, error) {
 	return loadFolder("procedures", "md")
 }
 
+// Mappings lists all control mapping files; the folder is optional.
+func Mappings() ([]File, error) {
+	return loadOptionalFolder("mappings", "yml")
+}
+
+// Applicability lists all control applicability declarations; the folder is optional.
+func Applicability() ([]File, error) {
+	return loadOptionalFolder("applicability", "yml")
+}
+
+// Audits lists all audit definitions; the folder is optional.
+func Audits() ([]File, error) {
+	return loadOptionalFolder("audits", "yml")
+}
+
+// Tickets lists all tickets of the local ticket system; the folder is optional.
+func Tickets() ([]File, error) {
+	return loadOptionalFolder("tickets", "md")
+}
+
+// TicketsRoot is the absolute path of the local ticket system folder.
+func TicketsRoot() string {
+	folder := "tickets"
+	if customFolder, isPresent := config.Config().CustomFolders[folder]; isPresent {
+		folder = customFolder
+	}
+	abs, err := filepath.Abs(filepath.Join(".", folder))
+	if err != nil {
+		return folder
+	}
+	return abs
+}
+
+// Evidence lists all files within the evidence tree, including YAML sidecars;
+// the folder is optional.
+func Evidence() ([]File, error) {
+	folder := evidenceFolder()
+
+	var files []File
+	err := filepath.Walk(filepath.Join(".", folder), func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".") || strings.HasPrefix(strings.ToUpper(info.Name()), "README") {
+			return nil
+		}
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return errors.Wrap(err, "unable to load file: "+info.Name())
+		}
+		files = append(files, File{abs, info})
+		return nil
+	})
+	if err != nil && os.IsNotExist(err) {
+		return []File{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load files for: "+folder)
+	}
+	return files, nil
+}
+
+// EvidenceRoot is the absolute path of the evidence tree.
+func EvidenceRoot() string {
+	abs, err := filepath.Abs(filepath.Join(".", evidenceFolder()))
+	if err != nil {
+		return evidenceFolder()
+	}
+	return abs
+}
+
+func evidenceFolder() string {
+	if customFolder, isPresent := config.Config().CustomFolders["evidence"]; isPresent {
+		return customFolder
+	}
+	return "evidence"
+}
+
+func loadOptionalFolder(defaultFolder string, format string) ([]File, error) {
+	files, err := loadFolder(defaultFolder, format)
+	if err != nil && os.IsNotExist(errors.Cause(err)) {
+		return []File{}, nil
+	}
+	return files, err
+}
+
 func loadFolder(defaultFolder string, format string) ([]File, error) {
 	customFolder, isPresent := config.Config().CustomFolders[defaultFolder]
 	if isPresent {
@@ -0,0 +1,413 @@
+package azuredevops
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/strongdm/comply/internal/model"
+)
+
+const (
+	cfgURL          = "url"
+	cfgProject      = "project"
+	cfgToken        = "token"
+	cfgWorkItemType = "workItemType"
+)
+
+var prompts = map[string]string{
+	cfgURL:          "Azure DevOps Organization URL",
+	cfgProject:      "Azure DevOps Project",
+	cfgToken:        "Azure DevOps Personal Access Token",
+	cfgWorkItemType: "Azure DevOps Work Item Type",
+}
+
+// apiVersion is the version of the work item tracking REST API in use.
+const apiVersion = "5.0"
+
+// Prompts are human-readable configuration element names
+func (a *azurePlugin) Prompts() map[string]string {
+	return prompts
+}
+
+// Register causes the Azure DevOps plugin to register itself
+func Register() {
+	model.Register(model.AzureDevOps, &azurePlugin{})
+}
+
+type azurePlugin struct {
+	url          string
+	project      string
+	token        string
+	workItemType string
+	labels       *model.LabelVocabulary
+
+	client *http.Client
+}
+
+// workItem is a work item as returned by the REST API.
+type workItem struct {
+	ID     int                    `json:"id"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+func (a *azurePlugin) Get(ID string) (*model.Ticket, error) {
+	if _, err := strconv.Atoi(ID); err != nil {
+		return nil, errors.Wrap(err, "malformed Azure DevOps work item ID: "+ID)
+	}
+
+	item := &workItem{}
+	err := a.do("GET", a.projectURL("_apis/wit/workitems/"+ID, nil), "", nil, item)
+	if err != nil {
+		return nil, errors.Wrap(err, "error during Get")
+	}
+	return toTicket(item, a.labels), nil
+}
+
+func (a *azurePlugin) Configured() bool {
+	return a.url != "" && a.project != "" && a.token != "" && a.workItemType != ""
+}
+
+func (a *azurePlugin) Links() model.TicketLinks {
+	links := model.TicketLinks{}
+	comply := tagged(a.labels.Label(model.LabelComply))
+	audit := tagged(a.labels.Label(model.LabelAudit))
+	procedure := tagged(a.labels.Label(model.LabelProcedure))
+	links.ProcedureAll = a.queryLink(procedure)
+	links.ProcedureOpen = a.queryLink(procedure + " AND " + open)
+	links.AuditAll = a.queryLink(comply + " AND " + audit)
+	links.AuditOpen = a.queryLink(comply + " AND " + audit + " AND " + open)
+	return links
+}
+
+// queryLink opens an ad-hoc query in the Azure DevOps UI.
+func (a *azurePlugin) queryLink(where string) string {
+	return a.projectURL("_queries/query/", url.Values{"wiql": []string{wiql(where)}})
+}
+
+func (a *azurePlugin) Configure(cfg map[string]interface{}) error {
+	var err error
+
+	if a.url, err = getCfg(cfg, cfgURL); err != nil {
+		return err
+	}
+	a.url = strings.TrimSuffix(a.url, "/")
+	if a.project, err = getCfg(cfg, cfgProject); err != nil {
+		return err
+	}
+	if a.token, err = getCfg(cfg, cfgToken); err != nil {
+		return err
+	}
+	if a.workItemType, err = getCfg(cfg, cfgWorkItemType); err != nil {
+		return err
+	}
+	a.labels = model.Labels()
+
+	return nil
+}
+
+func getCfg(cfg map[string]interface{}, k string) (string, error) {
+	v, ok := cfg[k]
+	if !ok {
+		return "", errors.New("Missing key: " + k)
+	}
+
+	vS, ok := v.(string)
+	if !ok {
+		return "", errors.New("Malformatted key: " + k)
+	}
+	return vS, nil
+}
+
+// open matches work items that are not in a completed state.
+const open = "[System.State] NOT IN ('Closed', 'Done', 'Removed')"
+
+func (a *azurePlugin) FindOpen() ([]*model.Ticket, error) {
+	tickets, err := a.query(open)
+	if err != nil {
+		return nil, errors.Wrap(err, "error during FindOpen")
+	}
+	return tickets, nil
+}
+
+// FindByTag finds tickets tagged "name:value".
+func (a *azurePlugin) FindByTag(name, value string) ([]*model.Ticket, error) {
+	return a.FindByTagName(fmt.Sprintf("%s:%s", name, value))
+}
+
+func (a *azurePlugin) FindByTagName(name string) ([]*model.Ticket, error) {
+	return a.FindByTagNameSince(name, time.Time{})
+}
+
+func (a *azurePlugin) FindByTagNameSince(name string, since time.Time) ([]*model.Ticket, error) {
+	where := tagged(a.labels.Label(name))
+	if !since.IsZero() {
+		where += fmt.Sprintf(" AND [System.ChangedDate] >= '%s'", since.UTC().Format(time.RFC3339))
+	}
+	tickets, err := a.query(where)
+	if err != nil {
+		return nil, errors.Wrap(err, "error during FindByTagNameSince")
+	}
+	return tickets, nil
+}
+
+func (a *azurePlugin) LinkFor(t *model.Ticket) string {
+	return a.projectURL("_workitems/edit/"+t.ID, nil)
+}
+
+// Create maps the priority to the 1-4 work item priority. Components become
+// "component:value" tags, as area paths must already exist.
+func (a *azurePlugin) Create(ticket *model.Ticket, labels []string) error {
+	if ticket.Component != "" {
+		labels = append(labels, "component:"+ticket.Component)
+	}
+	patch := []map[string]string{
+		{"op": "add", "path": "/fields/System.Title", "value": ticket.Name},
+		{"op": "add", "path": "/fields/System.Description", "value": toHTML(ticket.Body)},
+		{"op": "add", "path": "/fields/System.Tags", "value": strings.Join(a.labels.Labels(labels), "; ")},
+	}
+	add := func(field, value string) {
+		patch = append(patch, map[string]string{"op": "add", "path": "/fields/" + field, "value": value})
+	}
+	if ticket.Assignee != "" {
+		add("System.AssignedTo", ticket.Assignee)
+	}
+	if ticket.Priority != "" {
+		priority, ok := priorities[strings.ToLower(ticket.Priority)]
+		if !ok {
+			return errors.New("unknown Azure DevOps priority: " + ticket.Priority)
+		}
+		add("Microsoft.VSTS.Common.Priority", priority)
+	}
+	if ticket.DueDate != nil {
+		add("Microsoft.VSTS.Scheduling.DueDate", ticket.DueDate.UTC().Format(time.RFC3339))
+	}
+	body, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+
+	u := a.projectURL("_apis/wit/workitems/$"+url.PathEscape(a.workItemType), nil)
+	err = a.do("POST", u, "application/json-patch+json", body, nil)
+	if err != nil {
+		return errors.Wrap(err, "unable to create ticket")
+	}
+	return nil
+}
+
+// priorities maps priority names and numbers to work item priorities.
+var priorities = map[string]string{
+	"1": "1", "critical": "1", "urgent": "1",
+	"2": "2", "high": "2",
+	"3": "3", "medium": "3", "normal": "3",
+	"4": "4", "low": "4",
+}
+
+// batchSize is the maximum number of work items fetched per request.
+const batchSize = 200
+
+// query runs a WIQL query within the project and fetches the matching work
+// items in batches.
+func (a *azurePlugin) query(where string) ([]*model.Ticket, error) {
+	body, err := json.Marshal(map[string]string{"query": wiql(where)})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &struct {
+		WorkItems []struct {
+			ID int `json:"id"`
+		} `json:"workItems"`
+	}{}
+	u := a.projectURL("_apis/wit/wiql", url.Values{"timePrecision": []string{"true"}})
+	if err = a.do("POST", u, "application/json", body, result); err != nil {
+		return nil, err
+	}
+
+	var tickets []*model.Ticket
+	for start := 0; start < len(result.WorkItems); start += batchSize {
+		end := start + batchSize
+		if end > len(result.WorkItems) {
+			end = len(result.WorkItems)
+		}
+		var ids []string
+		for _, w := range result.WorkItems[start:end] {
+			ids = append(ids, strconv.Itoa(w.ID))
+		}
+
+		batch := &struct {
+			Value []*workItem `json:"value"`
+		}{}
+		u := a.projectURL("_apis/wit/workitems", url.Values{"ids": []string{strings.Join(ids, ",")}})
+		if err = a.do("GET", u, "", nil, batch); err != nil {
+			return nil, err
+		}
+		for _, item := range batch.Value {
+			tickets = append(tickets, toTicket(item, a.labels))
+		}
+	}
+	return tickets, nil
+}
+
+// wiql selects work items of the project ordered by ID.
+func wiql(where string) string {
+	return fmt.Sprintf("SELECT [System.Id] FROM WorkItems WHERE [System.TeamProject] = @project AND %s ORDER BY [System.Id] DESC", where)
+}
+
+// tagged matches work items carrying the tag.
+func tagged(tag string) string {
+	return fmt.Sprintf("[System.Tags] CONTAINS '%s'", strings.Replace(tag, "'", "''", -1))
+}
+
+func (a *azurePlugin) projectURL(path string, query url.Values) string {
+	if query == nil {
+		query = url.Values{}
+	}
+	if strings.HasPrefix(path, "_apis/") {
+		query.Set("api-version", apiVersion)
+	}
+	u := fmt.Sprintf("%s/%s/%s", a.url, url.PathEscape(a.project), path)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// backoff retries requests rejected by the Azure DevOps rate limit.
+var backoff = &model.Backoff{System: "Azure DevOps"}
+
+// do performs an authenticated request, decoding the JSON response into v
+// and backing off while Azure DevOps responds 429 Too Many Requests.
+func (a *azurePlugin) do(method, u, contentType string, body []byte, v interface{}) error {
+	client := a.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var resp *http.Response
+	var data []byte
+	err := backoff.Retry(func() error {
+		req, err := http.NewRequest(method, u, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		// personal access tokens are sent as the password with an empty username
+		req.SetBasicAuth("", a.token)
+		req.Header.Set("Accept", "application/json")
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+
+		resp, err = client.Do(req)
+		if err != nil {
+			return err
+		}
+		data, err = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		return err
+	}, func(err error) (time.Duration, bool) {
+		return retryAfter(resp, err)
+	})
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s: %d %s", method, u, resp.StatusCode, errorMessage(data))
+	}
+
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
+// retryAfter detects Azure DevOps responding 429 Too Many Requests, waiting
+// for the interval advised by the Retry-After header.
+func retryAfter(resp *http.Response, err error) (time.Duration, bool) {
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	return 0, true
+}
+
+func errorMessage(data []byte) string {
+	e := &struct {
+		Message string `json:"message"`
+	}{}
+	if err := json.Unmarshal(data, e); err != nil || e.Message == "" {
+		return strings.TrimSpace(string(data))
+	}
+	return e.Message
+}
+
+func toTicket(item *workItem, v *model.LabelVocabulary) *model.Ticket {
+	t := &model.Ticket{Attributes: make(map[string]interface{})}
+	t.ID = strconv.Itoa(item.ID)
+	t.Name = field(item, "System.Title")
+	t.Body = fromHTML(field(item, "System.Description"))
+	t.CreatedAt = timeField(item, "System.CreatedDate")
+	t.UpdatedAt = timeField(item, "System.ChangedDate")
+	t.ClosedAt = timeField(item, "Microsoft.VSTS.Common.ClosedDate")
+	t.State = toState(field(item, "System.State"))
+
+	for _, tag := range strings.Split(field(item, "System.Tags"), ";") {
+		if tag = strings.TrimSpace(tag); tag == "" {
+			continue
+		}
+		if canonical, ok := v.Canonical(tag); ok {
+			t.SetBool(canonical)
+		}
+	}
+	return t
+}
+
+func toState(state string) model.TicketState {
+	switch state {
+	case "Closed", "Done", "Removed":
+		return model.Closed
+	}
+	return model.Open
+}
+
+func field(item *workItem, name string) string {
+	s, _ := item.Fields[name].(string)
+	return s
+}
+
+func timeField(item *workItem, name string) *time.Time {
+	t, err := time.Parse(time.RFC3339, field(item, name))
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// toHTML formats a ticket body for the HTML description field.
+func toHTML(body string) string {
+	return strings.Replace(html.EscapeString(body), "\n", "<br>", -1)
+}
+
+var (
+	lineBreakRE = regexp.MustCompile(`(?i)<br\s*/?>|</div>|</p>`)
+	tagRE       = regexp.MustCompile(`<[^>]*>`)
+)
+
+// fromHTML recovers the plain text of a description, preserving line breaks
+// so ticket metadata can be parsed.
+func fromHTML(description string) string {
+	text := lineBreakRE.ReplaceAllString(description, "\n")
+	text = tagRE.ReplaceAllString(text, "")
+	return html.UnescapeString(text)
+}
@@ -0,0 +1,254 @@
+package azuredevops
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/strongdm/comply/internal/model"
+)
+
+// fakeBoards is a local stand-in for the Azure DevOps work item API.
+type fakeBoards struct {
+	t       *testing.T
+	items   map[int]*workItem
+	queries []string
+	// limited is the number of requests to reject with 429 Too Many Requests
+	limited int
+}
+
+var taggedRE = regexp.MustCompile(`\[System\.Tags\] CONTAINS '([^']*)'`)
+
+func (f *fakeBoards) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, password, _ := r.BasicAuth(); password != "token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if r.URL.Query().Get("api-version") != apiVersion {
+		f.t.Errorf("missing api-version: %s", r.URL)
+	}
+	if f.limited > 0 {
+		f.limited--
+		w.Header().Set("Retry-After", "15")
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/acme/Compliance/_apis/wit/wiql":
+		body := &struct{ Query string }{}
+		json.NewDecoder(r.Body).Decode(body)
+		f.queries = append(f.queries, body.Query)
+
+		result := map[string][]map[string]int{"workItems": {}}
+		for id := len(f.items); id > 0; id-- {
+			if match := taggedRE.FindStringSubmatch(body.Query); match != nil && !strings.Contains(field(f.items[id], "System.Tags"), match[1]) {
+				continue
+			}
+			result["workItems"] = append(result["workItems"], map[string]int{"id": id})
+		}
+		json.NewEncoder(w).Encode(result)
+
+	case r.Method == "GET" && r.URL.Path == "/acme/Compliance/_apis/wit/workitems":
+		var value []*workItem
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			n, _ := strconv.Atoi(id)
+			value = append(value, f.items[n])
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"count": len(value), "value": value})
+
+	case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/acme/Compliance/_apis/wit/workitems/"):
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/acme/Compliance/_apis/wit/workitems/"))
+		item, ok := f.items[n]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "TF401232: Work item does not exist"}`)
+			return
+		}
+		json.NewEncoder(w).Encode(item)
+
+	case r.Method == "POST" && r.URL.Path == "/acme/Compliance/_apis/wit/workitems/$Task":
+		if r.Header.Get("Content-Type") != "application/json-patch+json" {
+			f.t.Errorf("unexpected content type: %s", r.Header.Get("Content-Type"))
+		}
+		var patch []map[string]string
+		json.NewDecoder(r.Body).Decode(&patch)
+		item := &workItem{ID: len(f.items) + 1, Fields: map[string]interface{}{
+			"System.State":       "To Do",
+			"System.CreatedDate": "2018-06-01T00:00:00Z",
+			"System.ChangedDate": "2018-06-01T00:00:00Z",
+		}}
+		for _, op := range patch {
+			item.Fields[strings.TrimPrefix(op["path"], "/fields/")] = op["value"]
+		}
+		f.items[item.ID] = item
+		json.NewEncoder(w).Encode(item)
+
+	default:
+		f.t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestPlugin(t *testing.T) (*azurePlugin, *fakeBoards, func()) {
+	f := &fakeBoards{t: t, items: map[int]*workItem{
+		1: {ID: 1, Fields: map[string]interface{}{
+			"System.Title":                     "Onboard New User",
+			"System.Description":               "<div>Resolve this ticket by executing the following steps:</div><div><br></div><div>---</div><div>Procedure-ID: onboard</div>",
+			"System.State":                     "Closed",
+			"System.Tags":                      "comply; comply-procedure",
+			"System.CreatedDate":               "2018-04-01T00:00:00Z",
+			"System.ChangedDate":               "2018-04-02T00:00:00Z",
+			"Microsoft.VSTS.Common.ClosedDate": "2018-04-02T00:00:00Z",
+		}},
+		2: {ID: 2, Fields: map[string]interface{}{
+			"System.Title": "Unrelated",
+			"System.State": "Active",
+		}},
+	}}
+	server := httptest.NewServer(f)
+
+	a := &azurePlugin{}
+	err := a.Configure(map[string]interface{}{
+		cfgURL:          server.URL + "/acme/",
+		cfgProject:      "Compliance",
+		cfgToken:        "token",
+		cfgWorkItemType: "Task",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a, f, server.Close
+}
+
+func TestCreateAndFind(t *testing.T) {
+	a, f, done := newTestPlugin(t)
+	defer done()
+
+	err := a.Create(&model.Ticket{Name: "Apply OS patches", Body: "Patch <all> hosts\n\n\n---\nProcedure-ID: patch"}, []string{"comply", "comply-procedure"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.items[3].Fields["System.Tags"] != "comply; comply-procedure" {
+		t.Errorf("unexpected tags: %v", f.items[3].Fields["System.Tags"])
+	}
+
+	tickets, err := a.FindByTagName("comply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 2 {
+		t.Fatalf("expected 2 tickets, got %d", len(tickets))
+	}
+
+	created := tickets[0]
+	if created.ID != "3" || created.State != model.Open || created.ProcedureID() != "patch" || !created.Bool("comply-procedure") {
+		t.Errorf("unexpected ticket: %+v", created)
+	}
+	if !strings.HasPrefix(created.Body, "Patch <all> hosts\n") {
+		t.Errorf("body not preserved: %q", created.Body)
+	}
+
+	closed := tickets[1]
+	if closed.State != model.Closed || closed.ProcedureID() != "onboard" || closed.ClosedAt == nil || closed.UpdatedAt == nil {
+		t.Errorf("unexpected ticket: %+v", closed)
+	}
+}
+
+func TestCreateFields(t *testing.T) {
+	a, f, done := newTestPlugin(t)
+	defer done()
+
+	due := time.Date(2018, 6, 15, 12, 0, 0, 0, time.UTC)
+	ticket := &model.Ticket{
+		Name:      "Apply OS patches",
+		Body:      "Procedure-ID: patch",
+		Assignee:  "jdoe@example.com",
+		Priority:  "High",
+		Component: "infra",
+		DueDate:   &due,
+	}
+	if err := a.Create(ticket, []string{"comply"}); err != nil {
+		t.Fatal(err)
+	}
+	fields := f.items[3].Fields
+	if fields["System.AssignedTo"] != "jdoe@example.com" || fields["Microsoft.VSTS.Common.Priority"] != "2" || fields["Microsoft.VSTS.Scheduling.DueDate"] != "2018-06-15T12:00:00Z" {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+	if fields["System.Tags"] != "comply; component:infra" {
+		t.Errorf("unexpected tags: %v", fields["System.Tags"])
+	}
+
+	ticket.Priority = "whenever"
+	if err := a.Create(ticket, []string{"comply"}); err == nil {
+		t.Error("expected unknown priority error")
+	}
+}
+
+func TestFindSince(t *testing.T) {
+	a, f, done := newTestPlugin(t)
+	defer done()
+
+	_, err := a.FindByTagNameSince("comply", time.Date(2018, 4, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.queries) != 1 || !strings.Contains(f.queries[0], "[System.ChangedDate] >= '2018-04-01T12:00:00Z'") {
+		t.Errorf("unexpected query: %v", f.queries)
+	}
+
+	_, err = a.FindByTag("procedure", "it's")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(f.queries[1], "[System.Tags] CONTAINS 'procedure:it''s'") {
+		t.Errorf("unexpected query: %s", f.queries[1])
+	}
+}
+
+func TestGet(t *testing.T) {
+	a, _, done := newTestPlugin(t)
+	defer done()
+
+	ticket, err := a.Get("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.Name != "Onboard New User" || ticket.State != model.Closed {
+		t.Errorf("unexpected ticket: %+v", ticket)
+	}
+	if link := a.LinkFor(ticket); link != a.url+"/Compliance/_workitems/edit/1" {
+		t.Errorf("unexpected link: %s", link)
+	}
+
+	if _, err := a.Get("9"); err == nil || !strings.Contains(err.Error(), "TF401232") {
+		t.Errorf("expected missing work item error, got %v", err)
+	}
+}
+
+func TestBacksOff(t *testing.T) {
+	var waits []time.Duration
+	backoff.Sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { backoff.Sleep = nil }()
+
+	a, f, done := newTestPlugin(t)
+	defer done()
+	f.limited = 2
+
+	tickets, err := a.FindOpen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 2 {
+		t.Errorf("expected 2 tickets, got %d", len(tickets))
+	}
+	if len(waits) != 2 || waits[0] != 15*time.Second {
+		t.Errorf("unexpected backoff: %v", waits)
+	}
+}
@@ -3,10 +3,12 @@ package github
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/go-github/github"
 	"github.com/pkg/errors"
@@ -40,6 +42,7 @@ type githubPlugin struct {
 	token    string
 	username string
 	reponame string
+	labels   *model.LabelVocabulary
 
 	clientMu sync.Mutex
 	client   *github.Client
@@ -62,7 +65,17 @@ func (g *githubPlugin) api() *github.Client {
 }
 
 func (g *githubPlugin) Get(ID string) (*model.Ticket, error) {
-	return nil, nil
+	number, err := strconv.Atoi(ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed GitHub issue number: "+ID)
+	}
+
+	issue, _, err := g.api().Issues.Get(context.Background(), g.username, g.reponame, number)
+	if err != nil {
+		return nil, errors.Wrap(err, "error during Get")
+	}
+
+	return toTicket(issue, g.labels), nil
 }
 
 func (g *githubPlugin) Configured() bool {
@@ -70,11 +83,15 @@ func (g *githubPlugin) Configured() bool {
 }
 
 func (g *githubPlugin) Links() model.TicketLinks {
+	comply := url.QueryEscape(g.labels.Label(model.LabelComply))
+	audit := url.QueryEscape(g.labels.Label(model.LabelAudit))
+	procedure := url.QueryEscape(g.labels.Label(model.LabelProcedure))
+
 	links := model.TicketLinks{}
-	links.AuditAll = fmt.Sprintf("https://github.com/%s/%s/issues?q=is%%3Aissue+is%%3Aopen+label%%3Acomply+label%%3Aaudit", g.username, g.reponame)
-	links.AuditOpen = fmt.Sprintf("https://github.com/%s/%s/issues?q=is%%3Aissue+is%%3Aopen+label%%3Acomply+label%%3Aaudit", g.username, g.reponame)
-	links.ProcedureAll = fmt.Sprintf("https://github.com/%s/%s/issues?q=is%%3Aissue+label%%3Acomply+label%%3Acomply-procedure", g.username, g.reponame)
-	links.ProcedureOpen = fmt.Sprintf("https://github.com/%s/%s/issues?q=is%%3Aissue+is%%3Aopen+label%%3Acomply+label%%3Acomply-procedure", g.username, g.reponame)
+	links.AuditAll = fmt.Sprintf("https://github.com/%s/%s/issues?q=is%%3Aissue+is%%3Aopen+label%%3A%s+label%%3A%s", g.username, g.reponame, comply, audit)
+	links.AuditOpen = fmt.Sprintf("https://github.com/%s/%s/issues?q=is%%3Aissue+is%%3Aopen+label%%3A%s+label%%3A%s", g.username, g.reponame, comply, audit)
+	links.ProcedureAll = fmt.Sprintf("https://github.com/%s/%s/issues?q=is%%3Aissue+label%%3A%s+label%%3A%s", g.username, g.reponame, comply, procedure)
+	links.ProcedureOpen = fmt.Sprintf("https://github.com/%s/%s/issues?q=is%%3Aissue+is%%3Aopen+label%%3A%s+label%%3A%s", g.username, g.reponame, comply, procedure)
 	return links
 }
 
@@ -90,6 +107,7 @@ func (g *githubPlugin) Configure(cfg map[string]interface{}) error {
 	if g.reponame, err = getCfg(cfg, cfgRepo); err != nil {
 		return err
 	}
+	g.labels = model.Labels()
 
 	return nil
 }
@@ -111,7 +129,7 @@ func getCfg(cfg map[string]interface{}, k string) (string, error) {
 }
 
 func (g *githubPlugin) FindOpen() ([]*model.Ticket, error) {
-	issues, _, err := g.api().Issues.ListByRepo(context.Background(), g.username, g.reponame, &github.IssueListByRepoOptions{
+	issues, err := g.listIssues(&github.IssueListByRepoOptions{
 		State: "open",
 	})
 
@@ -119,59 +137,162 @@ func (g *githubPlugin) FindOpen() ([]*model.Ticket, error) {
 		return nil, errors.Wrap(err, "error during FindOpen")
 	}
 
-	return toTickets(issues), nil
+	return toTickets(issues, g.labels), nil
 }
 
+// FindByTag finds tickets labeled "name:value".
 func (g *githubPlugin) FindByTag(name, value string) ([]*model.Ticket, error) {
-	panic("not implemented")
+	return g.FindByTagName(fmt.Sprintf("%s:%s", name, value))
 }
 
 func (g *githubPlugin) FindByTagName(name string) ([]*model.Ticket, error) {
-	issues, _, err := g.api().Issues.ListByRepo(context.Background(), g.username, g.reponame, &github.IssueListByRepoOptions{
+	return g.FindByTagNameSince(name, time.Time{})
+}
+
+func (g *githubPlugin) FindByTagNameSince(name string, since time.Time) ([]*model.Ticket, error) {
+	issues, err := g.listIssues(&github.IssueListByRepoOptions{
 		State:  "all",
-		Labels: []string{name},
+		Labels: []string{g.labels.Label(name)},
+		Since:  since,
 	})
 
 	if err != nil {
-		return nil, errors.Wrap(err, "error during FindOpen")
+		return nil, errors.Wrap(err, "error during FindByTagName")
+	}
+
+	return toTickets(issues, g.labels), nil
+}
+
+// pageSize is the maximum page size permitted by the GitHub API.
+const pageSize = 100
+
+// backoff retries requests rejected by the GitHub rate limit.
+var backoff = &model.Backoff{System: "GitHub"}
+
+// listIssues follows pagination to list every matching issue.
+func (g *githubPlugin) listIssues(opts *github.IssueListByRepoOptions) ([]*github.Issue, error) {
+	opts.PerPage = pageSize
+
+	var all []*github.Issue
+	for {
+		var issues []*github.Issue
+		var resp *github.Response
+		err := backoff.Retry(func() (err error) {
+			issues, resp, err = g.api().Issues.ListByRepo(context.Background(), g.username, g.reponame, opts)
+			return err
+		}, retryAfter)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, issues...)
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opts.Page = resp.NextPage
 	}
+}
 
-	return toTickets(issues), nil
+// retryAfter detects the GitHub rate limit, waiting until it resets or for
+// the interval advised for abuse detection.
+func retryAfter(err error) (time.Duration, bool) {
+	switch e := err.(type) {
+	case *github.RateLimitError:
+		return time.Until(e.Rate.Reset.Time), true
+	case *github.AbuseRateLimitError:
+		if e.RetryAfter != nil {
+			return *e.RetryAfter, true
+		}
+		return 0, true
+	}
+	return 0, false
 }
 
 func (g *githubPlugin) LinkFor(t *model.Ticket) string {
-	// return fmt.Sprintf("https://github.com/strongdm/comply/issues/%s", t.ID)
-	panic("not implemented")
+	return fmt.Sprintf("https://github.com/%s/%s/issues/%s", g.username, g.reponame, t.ID)
 }
 
+// Create files the ticket in the open milestone due on its due date, or
+// labels it "due:YYYY-MM-DD" if there is none. GitHub has no
+// priorities or components, so these become "priority:value" and
+// "component:value" labels.
 func (g *githubPlugin) Create(ticket *model.Ticket, labels []string) error {
-	_, _, err := g.api().Issues.Create(context.Background(), g.username, g.reponame, &github.IssueRequest{
-		Title:  &ticket.Name,
-		Body:   &ticket.Body,
-		Labels: &labels,
-	})
+	req := &github.IssueRequest{
+		Title: &ticket.Name,
+		Body:  &ticket.Body,
+	}
+	if ticket.Assignee != "" {
+		req.Assignees = &[]string{ticket.Assignee}
+	}
+	if ticket.DueDate != nil {
+		milestone, err := g.milestoneDueOn(*ticket.DueDate)
+		if err != nil {
+			return errors.Wrap(err, "unable to find milestone")
+		}
+		req.Milestone = milestone
+		if milestone == nil {
+			labels = append(labels, "due:"+ticket.DueDate.Format(model.DateFormat))
+		}
+	}
+	if ticket.Priority != "" {
+		labels = append(labels, "priority:"+ticket.Priority)
+	}
+	if ticket.Component != "" {
+		labels = append(labels, "component:"+ticket.Component)
+	}
+	labels = g.labels.Labels(labels)
+	req.Labels = &labels
+
+	_, _, err := g.api().Issues.Create(context.Background(), g.username, g.reponame, req)
 	return err
 }
 
-func toTickets(issues []*github.Issue) []*model.Ticket {
+// milestoneDueOn is the number of an open milestone due on the given day, or
+// nil if there is none; later milestones would extend the deadline.
+func (g *githubPlugin) milestoneDueOn(due time.Time) (*int, error) {
+	milestones, _, err := g.api().Issues.ListMilestones(context.Background(), g.username, g.reponame, &github.MilestoneListOptions{
+		State:       "open",
+		Sort:        "due_on",
+		Direction:   "asc",
+		ListOptions: github.ListOptions{PerPage: pageSize},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	day := due.Format(model.DateFormat)
+	for _, m := range milestones {
+		if m.DueOn != nil && m.DueOn.UTC().Format(model.DateFormat) == day {
+			return m.Number, nil
+		}
+	}
+	return nil, nil
+}
+
+func toTickets(issues []*github.Issue, v *model.LabelVocabulary) []*model.Ticket {
 	var tickets []*model.Ticket
 	for _, i := range issues {
-		tickets = append(tickets, toTicket(i))
+		tickets = append(tickets, toTicket(i, v))
 	}
 	return tickets
 }
 
-func toTicket(i *github.Issue) *model.Ticket {
+func toTicket(i *github.Issue, v *model.LabelVocabulary) *model.Ticket {
 	t := &model.Ticket{Attributes: make(map[string]interface{})}
 	t.ID = strconv.Itoa(*i.Number)
 	t.Name = ss(i.Title)
 	t.Body = ss(i.Body)
 	t.CreatedAt = i.CreatedAt
+	t.UpdatedAt = i.UpdatedAt
+	t.ClosedAt = i.ClosedAt
 	t.State = toState(ss(i.State))
 
 	for _, l := range i.Labels {
-		if l.Name != nil {
-			t.SetBool(*l.Name)
+		if l.Name == nil {
+			continue
+		}
+		if canonical, ok := v.Canonical(*l.Name); ok {
+			t.SetBool(canonical)
 		}
 	}
 	return t
@@ -0,0 +1,238 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/strongdm/comply/internal/model"
+)
+
+// newTestPlugin serves recorded responses from testdata, keyed by request
+// path including the query string, or by path alone.
+func newTestPlugin(t *testing.T, fixtures map[string]string) (*githubPlugin, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fixture, ok := fixtures[r.Method+" "+r.URL.RequestURI()]
+		if !ok {
+			fixture, ok = fixtures[r.Method+" "+r.URL.Path]
+		}
+		if !ok {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			http.NotFound(w, r)
+			return
+		}
+		body, err := ioutil.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	g := &githubPlugin{token: "token", username: "acme", reponame: "compliance", client: client}
+	return g, server.Close
+}
+
+func TestGet(t *testing.T) {
+	g, done := newTestPlugin(t, map[string]string{
+		"GET /repos/acme/compliance/issues/7": "issue.json",
+	})
+	defer done()
+
+	ticket, err := g.Get("7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.ID != "7" || ticket.ProcedureID() != "patch" || ticket.State != model.Open || !ticket.Bool("comply-procedure") {
+		t.Errorf("unexpected ticket: %+v", ticket)
+	}
+	if link := g.LinkFor(ticket); link != "https://github.com/acme/compliance/issues/7" {
+		t.Errorf("unexpected link: %s", link)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	var created map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repos/acme/compliance/milestones":
+			if r.URL.Query().Get("sort") != "due_on" {
+				t.Errorf("unexpected milestone query: %s", r.URL)
+			}
+			fmt.Fprint(w, `[{"number": 1, "due_on": "2018-06-01T07:00:00Z"}, {"number": 2}, {"number": 3, "due_on": "2018-06-30T07:00:00Z"}]`)
+		case "POST /repos/acme/compliance/issues":
+			json.NewDecoder(r.Body).Decode(&created)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"number": 8}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	g := &githubPlugin{token: "token", username: "acme", reponame: "compliance", client: client}
+
+	due := time.Date(2018, 6, 30, 12, 0, 0, 0, time.UTC)
+	err := g.Create(&model.Ticket{
+		Name:      "Apply OS patches",
+		Body:      "Procedure-ID: patch",
+		Assignee:  "octocat",
+		Priority:  "high",
+		Component: "infra",
+		DueDate:   &due,
+	}, []string{"comply", "comply-procedure"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(created["labels"]) != "[comply comply-procedure priority:high component:infra]" {
+		t.Errorf("unexpected labels: %v", created["labels"])
+	}
+	if fmt.Sprint(created["assignees"]) != "[octocat]" || created["milestone"] != float64(3) {
+		t.Errorf("unexpected issue: %v", created)
+	}
+
+	// between milestones, as a later milestone would extend the deadline
+	created = nil
+	due = time.Date(2018, 6, 15, 12, 0, 0, 0, time.UTC)
+	err = g.Create(&model.Ticket{Name: "Apply OS patches", DueDate: &due}, []string{"comply"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(created["labels"]) != "[comply due:2018-06-15]" {
+		t.Errorf("expected due date label, got %v", created["labels"])
+	}
+	if _, ok := created["milestone"]; ok {
+		t.Errorf("expected no milestone: %v", created)
+	}
+}
+
+func TestFind(t *testing.T) {
+	g, done := newTestPlugin(t, map[string]string{
+		"GET /repos/acme/compliance/issues?per_page=100&state=open":                         "issues.json",
+		"GET /repos/acme/compliance/issues?labels=procedure%3Apatch&per_page=100&state=all": "issues.json",
+	})
+	defer done()
+
+	open, err := g.FindOpen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(open) != 2 {
+		t.Errorf("expected 2 tickets, got %d", len(open))
+	}
+
+	tagged, err := g.FindByTag("procedure", "patch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tagged) != 2 || tagged[1].State != model.Closed {
+		t.Errorf("unexpected tickets: %v", tagged)
+	}
+}
+
+func TestFindSince(t *testing.T) {
+	g, done := newTestPlugin(t, map[string]string{
+		"GET /repos/acme/compliance/issues?labels=comply&per_page=100&since=2018-04-01T00%3A00%3A00Z&state=all": "issues.json",
+	})
+	defer done()
+
+	tickets, err := g.FindByTagNameSince("comply", time.Date(2018, 4, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 2 {
+		t.Fatalf("expected 2 tickets, got %d", len(tickets))
+	}
+	if tickets[0].UpdatedAt == nil || tickets[0].ClosedAt != nil {
+		t.Errorf("unexpected timestamps for open ticket: %+v", tickets[0])
+	}
+	if tickets[1].ClosedAt == nil || !tickets[1].ClosedAt.Equal(time.Date(2018, 4, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected timestamps for closed ticket: %+v", tickets[1])
+	}
+}
+
+func TestFindPaginates(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if r.URL.Query().Get("per_page") != "100" {
+			t.Errorf("unexpected page size: %s", r.URL)
+		}
+		switch page {
+		case "", "1":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/acme/compliance/issues?page=2>; rel="next"`, server.URL))
+			fmt.Fprint(w, `[{"number": 3, "state": "open"}, {"number": 2, "state": "open"}]`)
+		case "2":
+			fmt.Fprint(w, `[{"number": 1, "state": "closed"}]`)
+		default:
+			t.Errorf("unexpected page: %s", page)
+		}
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	g := &githubPlugin{token: "token", username: "acme", reponame: "compliance", client: client}
+
+	tickets, err := g.FindByTagName("comply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 3 || tickets[2].ID != "1" {
+		t.Errorf("expected tickets from both pages, got %v", tickets)
+	}
+}
+
+func TestFindBacksOff(t *testing.T) {
+	var waits []time.Duration
+	backoff.Sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { backoff.Sleep = nil }()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("X-RateLimit-Limit", "5000")
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", fmt.Sprintf("%d", time.Now().Unix()))
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message": "API rate limit exceeded for 203.0.113.1."}`)
+			return
+		}
+		if requests == 2 {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message": "You have triggered an abuse detection mechanism", "documentation_url": "https://developer.github.com/v3/#abuse-rate-limits"}`)
+			return
+		}
+		fmt.Fprint(w, `[{"number": 1, "state": "open"}]`)
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	g := &githubPlugin{token: "token", username: "acme", reponame: "compliance", client: client}
+
+	tickets, err := g.FindOpen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 1 {
+		t.Errorf("expected 1 ticket, got %d", len(tickets))
+	}
+	if len(waits) != 2 || waits[0] != time.Second || waits[1] != 30*time.Second {
+		t.Errorf("unexpected backoff: %v", waits)
+	}
+}
@@ -0,0 +1,514 @@
+package linear
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/strongdm/comply/internal/model"
+)
+
+const (
+	cfgAPIKey    = "apiKey"
+	cfgTeam      = "team"
+	cfgWorkspace = "workspace"
+)
+
+var prompts = map[string]string{
+	cfgAPIKey:    "Linear API Key",
+	cfgTeam:      "Linear Team Key",
+	cfgWorkspace: "Linear Workspace URL Key",
+}
+
+// endpoint is the Linear GraphQL API.
+const endpoint = "https://api.linear.app/graphql"
+
+// Prompts are human-readable configuration element names
+func (l *linearPlugin) Prompts() map[string]string {
+	return prompts
+}
+
+// Register causes the Linear plugin to register itself
+func Register() {
+	model.Register(model.Linear, &linearPlugin{})
+}
+
+type linearPlugin struct {
+	apiKey    string
+	team      string
+	workspace string
+	labels    *model.LabelVocabulary
+
+	// endpoint and client are replaced in tests
+	endpoint string
+	client   *http.Client
+}
+
+// issueFields are the fields of an issue needed to build a ticket.
+const issueFields = `
+	identifier
+	title
+	description
+	createdAt
+	updatedAt
+	completedAt
+	canceledAt
+	state { type }
+	labels { nodes { name } }`
+
+type issue struct {
+	Identifier  string     `json:"identifier"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	CreatedAt   *time.Time `json:"createdAt"`
+	UpdatedAt   *time.Time `json:"updatedAt"`
+	CompletedAt *time.Time `json:"completedAt"`
+	CanceledAt  *time.Time `json:"canceledAt"`
+	State       struct {
+		Type string `json:"type"`
+	} `json:"state"`
+	Labels struct {
+		Nodes []label `json:"nodes"`
+	} `json:"labels"`
+}
+
+type label struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Get accepts an issue identifier such as "ENG-123".
+func (l *linearPlugin) Get(ID string) (*model.Ticket, error) {
+	result := &struct {
+		Issue *issue `json:"issue"`
+	}{}
+	err := l.graphql(`query($id: String!) { issue(id: $id) {`+issueFields+` } }`, map[string]interface{}{"id": ID}, result)
+	if err != nil {
+		return nil, errors.Wrap(err, "error during Get")
+	}
+	if result.Issue == nil {
+		return nil, errors.New("no Linear issue " + ID)
+	}
+	return toTicket(result.Issue, l.labels), nil
+}
+
+func (l *linearPlugin) Configured() bool {
+	return l.apiKey != "" && l.team != "" && l.workspace != ""
+}
+
+// Links open the team's issue views, as Linear views cannot be filtered by URL.
+func (l *linearPlugin) Links() model.TicketLinks {
+	links := model.TicketLinks{}
+	links.ProcedureAll = fmt.Sprintf("https://linear.app/%s/team/%s/all", l.workspace, l.team)
+	links.ProcedureOpen = fmt.Sprintf("https://linear.app/%s/team/%s/active", l.workspace, l.team)
+	links.AuditAll = links.ProcedureAll
+	links.AuditOpen = links.ProcedureOpen
+	return links
+}
+
+func (l *linearPlugin) Configure(cfg map[string]interface{}) error {
+	var err error
+
+	if l.apiKey, err = getCfg(cfg, cfgAPIKey); err != nil {
+		return err
+	}
+	if l.team, err = getCfg(cfg, cfgTeam); err != nil {
+		return err
+	}
+	if l.workspace, err = getCfg(cfg, cfgWorkspace); err != nil {
+		return err
+	}
+	l.labels = model.Labels()
+
+	return nil
+}
+
+func getCfg(cfg map[string]interface{}, k string) (string, error) {
+	v, ok := cfg[k]
+	if !ok {
+		return "", errors.New("Missing key: " + k)
+	}
+
+	vS, ok := v.(string)
+	if !ok {
+		return "", errors.New("Malformatted key: " + k)
+	}
+	return vS, nil
+}
+
+func (l *linearPlugin) FindOpen() ([]*model.Ticket, error) {
+	tickets, err := l.findIssues(map[string]interface{}{
+		"state": map[string]interface{}{"type": map[string]interface{}{"nin": []string{"completed", "canceled"}}},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error during FindOpen")
+	}
+	return tickets, nil
+}
+
+// FindByTag finds tickets labeled "name:value".
+func (l *linearPlugin) FindByTag(name, value string) ([]*model.Ticket, error) {
+	return l.FindByTagName(fmt.Sprintf("%s:%s", name, value))
+}
+
+func (l *linearPlugin) FindByTagName(name string) ([]*model.Ticket, error) {
+	return l.FindByTagNameSince(name, time.Time{})
+}
+
+func (l *linearPlugin) FindByTagNameSince(name string, since time.Time) ([]*model.Ticket, error) {
+	filter := map[string]interface{}{
+		"labels": map[string]interface{}{"name": map[string]interface{}{"eq": l.labels.Label(name)}},
+	}
+	if !since.IsZero() {
+		filter["updatedAt"] = map[string]interface{}{"gte": since.UTC().Format(time.RFC3339)}
+	}
+	tickets, err := l.findIssues(filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "error during FindByTagNameSince")
+	}
+	return tickets, nil
+}
+
+func (l *linearPlugin) LinkFor(t *model.Ticket) string {
+	return fmt.Sprintf("https://linear.app/%s/issue/%s", l.workspace, t.ID)
+}
+
+// Create creates labels missing from both the team and the workspace before
+// creating the issue. The assignee is matched by email or display name;
+// components become "component:value" labels.
+func (l *linearPlugin) Create(ticket *model.Ticket, labels []string) error {
+	if ticket.Component != "" {
+		labels = append(labels, "component:"+ticket.Component)
+	}
+	labels = l.labels.Labels(labels)
+
+	teamID, err := l.teamID()
+	if err != nil {
+		return errors.Wrap(err, "unable to fetch Linear team")
+	}
+	existing, err := l.labelIDs(teamID, labels)
+	if err != nil {
+		return errors.Wrap(err, "unable to fetch Linear labels")
+	}
+
+	var labelIDs []string
+	for _, name := range labels {
+		id, ok := existing[name]
+		if !ok {
+			result := &struct {
+				IssueLabelCreate struct {
+					IssueLabel label `json:"issueLabel"`
+				} `json:"issueLabelCreate"`
+			}{}
+			err = l.graphql(`mutation($input: IssueLabelCreateInput!) { issueLabelCreate(input: $input) { issueLabel { id name } } }`,
+				map[string]interface{}{"input": map[string]interface{}{"teamId": teamID, "name": name}}, result)
+			if err != nil {
+				return errors.Wrap(err, "unable to create Linear label "+name)
+			}
+			id = result.IssueLabelCreate.IssueLabel.ID
+		}
+		labelIDs = append(labelIDs, id)
+	}
+
+	input := map[string]interface{}{
+		"teamId":      teamID,
+		"title":       ticket.Name,
+		"description": ticket.Body,
+		"labelIds":    labelIDs,
+	}
+	if ticket.Assignee != "" {
+		assigneeID, err := l.userID(ticket.Assignee)
+		if err != nil {
+			return err
+		}
+		input["assigneeId"] = assigneeID
+	}
+	if ticket.Priority != "" {
+		priority, ok := priorities[strings.ToLower(ticket.Priority)]
+		if !ok {
+			return errors.New("unknown Linear priority: " + ticket.Priority)
+		}
+		input["priority"] = priority
+	}
+	if ticket.DueDate != nil {
+		input["dueDate"] = ticket.DueDate.Format(model.DateFormat)
+	}
+	err = l.graphql(`mutation($input: IssueCreateInput!) { issueCreate(input: $input) { success } }`,
+		map[string]interface{}{"input": input}, nil)
+	if err != nil {
+		return errors.Wrap(err, "unable to create ticket")
+	}
+	return nil
+}
+
+// priorities maps priority names and numbers to Linear issue priorities.
+var priorities = map[string]int{
+	"0": 0, "none": 0,
+	"1": 1, "urgent": 1,
+	"2": 2, "high": 2,
+	"3": 3, "medium": 3, "normal": 3,
+	"4": 4, "low": 4,
+}
+
+// userID resolves a user by email or display name.
+func (l *linearPlugin) userID(name string) (string, error) {
+	result := &struct {
+		Users struct {
+			Nodes []struct {
+				ID string `json:"id"`
+			} `json:"nodes"`
+		} `json:"users"`
+	}{}
+	err := l.graphql(`query($name: String!) { users(filter: { or: [{ email: { eq: $name } }, { displayName: { eq: $name } }] }) { nodes { id } } }`,
+		map[string]interface{}{"name": name}, result)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to find Linear user "+name)
+	}
+	if len(result.Users.Nodes) == 0 {
+		return "", errors.New("unknown Linear user: " + name)
+	}
+	return result.Users.Nodes[0].ID, nil
+}
+
+// teamID resolves the configured team key to its ID.
+func (l *linearPlugin) teamID() (string, error) {
+	result := &struct {
+		Teams struct {
+			Nodes []struct {
+				ID string `json:"id"`
+			} `json:"nodes"`
+		} `json:"teams"`
+	}{}
+	err := l.graphql(`query($key: String!) { teams(filter: { key: { eq: $key } }) { nodes { id } } }`,
+		map[string]interface{}{"key": l.team}, result)
+	if err != nil {
+		return "", err
+	}
+	if len(result.Teams.Nodes) == 0 {
+		return "", fmt.Errorf("no Linear team with key %s", l.team)
+	}
+	return result.Teams.Nodes[0].ID, nil
+}
+
+// labelIDs finds the IDs of the named labels usable by the team: its own and
+// workspace labels, which have no team.
+func (l *linearPlugin) labelIDs(teamID string, names []string) (map[string]string, error) {
+	query := `query($names: [String!], $first: Int, $after: String) {
+		issueLabels(filter: { name: { in: $names } }, first: $first, after: $after) {
+			nodes { id name team { id } }
+			pageInfo { hasNextPage endCursor }
+		}
+	}`
+
+	ids := make(map[string]string)
+	variables := map[string]interface{}{"names": names, "first": pageSize}
+	for {
+		result := &struct {
+			IssueLabels struct {
+				Nodes []struct {
+					label
+					Team *struct {
+						ID string `json:"id"`
+					} `json:"team"`
+				} `json:"nodes"`
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+			} `json:"issueLabels"`
+		}{}
+		if err := l.graphql(query, variables, result); err != nil {
+			return nil, err
+		}
+
+		for _, lbl := range result.IssueLabels.Nodes {
+			if lbl.Team == nil || lbl.Team.ID == teamID {
+				ids[lbl.Name] = lbl.ID
+			}
+		}
+		if !result.IssueLabels.PageInfo.HasNextPage {
+			return ids, nil
+		}
+		variables["after"] = result.IssueLabels.PageInfo.EndCursor
+	}
+}
+
+// pageSize is the number of issues or labels requested per page.
+const pageSize = 100
+
+// findIssues follows pagination to list every issue of the team matching filter.
+func (l *linearPlugin) findIssues(filter map[string]interface{}) ([]*model.Ticket, error) {
+	filter["team"] = map[string]interface{}{"key": map[string]interface{}{"eq": l.team}}
+
+	query := `query($filter: IssueFilter, $first: Int, $after: String) {
+		issues(filter: $filter, first: $first, after: $after) {
+			nodes {` + issueFields + ` }
+			pageInfo { hasNextPage endCursor }
+		}
+	}`
+
+	var tickets []*model.Ticket
+	variables := map[string]interface{}{"filter": filter, "first": pageSize}
+	for {
+		result := &struct {
+			Issues struct {
+				Nodes    []*issue `json:"nodes"`
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+			} `json:"issues"`
+		}{}
+		if err := l.graphql(query, variables, result); err != nil {
+			return nil, err
+		}
+
+		for _, i := range result.Issues.Nodes {
+			tickets = append(tickets, toTicket(i, l.labels))
+		}
+		if !result.Issues.PageInfo.HasNextPage {
+			return tickets, nil
+		}
+		variables["after"] = result.Issues.PageInfo.EndCursor
+	}
+}
+
+// backoff retries requests rejected by the Linear rate limit.
+var backoff = &model.Backoff{System: "Linear"}
+
+type graphqlError struct {
+	Message    string `json:"message"`
+	Extensions struct {
+		Code string `json:"code"`
+	} `json:"extensions"`
+}
+
+type graphqlResult struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []graphqlError  `json:"errors"`
+}
+
+// graphql performs an authenticated GraphQL request, decoding the response
+// data into v and backing off while Linear reports a rate limit.
+func (l *linearPlugin) graphql(query string, variables map[string]interface{}, v interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+
+	u := l.endpoint
+	if u == "" {
+		u = endpoint
+	}
+	client := l.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var resp *http.Response
+	var result *graphqlResult
+	err = backoff.Retry(func() error {
+		req, err := http.NewRequest("POST", u, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", l.apiKey)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err = client.Do(req)
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		result = &graphqlResult{}
+		if err := json.Unmarshal(data, result); err != nil && resp.StatusCode != http.StatusTooManyRequests {
+			return fmt.Errorf("Linear responded %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+		}
+		return nil
+	}, func(err error) (time.Duration, bool) {
+		return retryAfter(resp, result, err)
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(result.Errors) > 0 {
+		var messages []string
+		for _, e := range result.Errors {
+			messages = append(messages, e.Message)
+		}
+		return errors.New(strings.Join(messages, "; "))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("Linear responded %d", resp.StatusCode)
+	}
+
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(result.Data, v)
+}
+
+// retryAfter detects Linear responding 429 Too Many Requests or a RATELIMITED
+// error, waiting until the limit resets.
+func retryAfter(resp *http.Response, result *graphqlResult, err error) (time.Duration, bool) {
+	if err != nil {
+		return 0, false
+	}
+	limited := resp.StatusCode == http.StatusTooManyRequests
+	for _, e := range result.Errors {
+		limited = limited || e.Extensions.Code == "RATELIMITED"
+	}
+	if !limited {
+		return 0, false
+	}
+	// the reset header is a UTC epoch in milliseconds
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Requests-Reset"), 10, 64); err == nil {
+		return time.Until(time.Unix(0, reset*int64(time.Millisecond))), true
+	}
+	return 0, true
+}
+
+func toTicket(i *issue, v *model.LabelVocabulary) *model.Ticket {
+	t := &model.Ticket{Attributes: make(map[string]interface{})}
+	t.ID = i.Identifier
+	t.Name = i.Title
+	t.Body = i.Description
+	t.CreatedAt = i.CreatedAt
+	t.UpdatedAt = i.UpdatedAt
+	t.State = toState(i.State.Type)
+	if t.State == model.Closed {
+		t.ClosedAt = i.CompletedAt
+		if t.ClosedAt == nil {
+			t.ClosedAt = i.CanceledAt
+		}
+	}
+
+	for _, l := range i.Labels.Nodes {
+		if canonical, ok := v.Canonical(l.Name); ok {
+			t.SetBool(canonical)
+		}
+	}
+	return t
+}
+
+// toState maps Linear workflow state types; backlog, unstarted, started and
+// triage states are open.
+func toState(stateType string) model.TicketState {
+	switch stateType {
+	case "completed", "canceled":
+		return model.Closed
+	}
+	return model.Open
+}
@@ -0,0 +1,189 @@
+package linear
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/strongdm/comply/internal/model"
+)
+
+type request struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// newTestPlugin serves GraphQL requests with respond, which receives each
+// request in turn.
+func newTestPlugin(t *testing.T, respond func(r *request) string) (*linearPlugin, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "lin_api_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprint(w, respond(req))
+	}))
+
+	l := &linearPlugin{endpoint: server.URL}
+	err := l.Configure(map[string]interface{}{
+		cfgAPIKey:    "lin_api_test",
+		cfgTeam:      "OPS",
+		cfgWorkspace: "acme",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l, server.Close
+}
+
+func TestCreate(t *testing.T) {
+	var created map[string]interface{}
+	var createdLabels []string
+	l, done := newTestPlugin(t, func(r *request) string {
+		switch {
+		case strings.Contains(r.Query, "teams("):
+			return `{"data": {"teams": {"nodes": [{"id": "team-1"}]}}}`
+		case strings.Contains(r.Query, "issueLabels("):
+			if r.Variables["after"] == nil {
+				// a workspace label
+				return `{"data": {"issueLabels": {"nodes": [{"id": "label-1", "name": "comply", "team": null}],
+					"pageInfo": {"hasNextPage": true, "endCursor": "cursor-1"}}}}`
+			}
+			// another team's label cannot be applied
+			return `{"data": {"issueLabels": {"nodes": [{"id": "label-9", "name": "comply-procedure", "team": {"id": "team-2"}}],
+				"pageInfo": {"hasNextPage": false}}}}`
+		case strings.Contains(r.Query, "issueLabelCreate"):
+			input := r.Variables["input"].(map[string]interface{})
+			createdLabels = append(createdLabels, input["name"].(string))
+			return `{"data": {"issueLabelCreate": {"issueLabel": {"id": "label-2", "name": "comply-procedure"}}}}`
+		case strings.Contains(r.Query, "users("):
+			return `{"data": {"users": {"nodes": [{"id": "user-1"}]}}}`
+		case strings.Contains(r.Query, "issueCreate"):
+			created = r.Variables["input"].(map[string]interface{})
+			return `{"data": {"issueCreate": {"success": true}}}`
+		}
+		t.Errorf("unexpected query: %s", r.Query)
+		return `{}`
+	})
+	defer done()
+
+	err := l.Create(&model.Ticket{Name: "Apply OS patches", Body: "Procedure-ID: patch"}, []string{"comply", "comply-procedure"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(createdLabels) != 1 || createdLabels[0] != "comply-procedure" {
+		t.Errorf("expected only the missing label to be created, got %v", createdLabels)
+	}
+	if created["teamId"] != "team-1" || created["title"] != "Apply OS patches" || fmt.Sprint(created["labelIds"]) != "[label-1 label-2]" {
+		t.Errorf("unexpected issue: %v", created)
+	}
+	if _, ok := created["assigneeId"]; ok {
+		t.Errorf("expected no assignee: %v", created)
+	}
+
+	due := time.Date(2018, 6, 15, 12, 0, 0, 0, time.UTC)
+	err = l.Create(&model.Ticket{Name: "Apply OS patches", Assignee: "jdoe@example.com", Priority: "High", DueDate: &due}, []string{"comply"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created["assigneeId"] != "user-1" || created["priority"] != float64(2) || created["dueDate"] != "2018-06-15" {
+		t.Errorf("unexpected issue: %v", created)
+	}
+
+	err = l.Create(&model.Ticket{Name: "Apply OS patches", Priority: "whenever"}, []string{"comply"})
+	if err == nil || !strings.Contains(err.Error(), "unknown Linear priority") {
+		t.Errorf("expected unknown priority error, got %v", err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	var filters []string
+	l, done := newTestPlugin(t, func(r *request) string {
+		filter, _ := json.Marshal(r.Variables["filter"])
+		filters = append(filters, string(filter))
+		if r.Variables["after"] == nil {
+			return `{"data": {"issues": {
+				"nodes": [{"identifier": "OPS-2", "title": "Apply OS patches", "description": "---\nProcedure-ID: patch", "state": {"type": "started"}, "labels": {"nodes": [{"name": "comply"}]}, "updatedAt": "2018-06-02T00:00:00.000Z"}],
+				"pageInfo": {"hasNextPage": true, "endCursor": "cursor-1"}}}}`
+		}
+		return `{"data": {"issues": {
+			"nodes": [{"identifier": "OPS-1", "title": "Onboard New User", "state": {"type": "canceled"}, "canceledAt": "2018-04-02T00:00:00.000Z", "labels": {"nodes": []}}],
+			"pageInfo": {"hasNextPage": false, "endCursor": "cursor-2"}}}}`
+	})
+	defer done()
+
+	tickets, err := l.FindByTagNameSince("comply", time.Date(2018, 4, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 2 {
+		t.Fatalf("expected tickets from both pages, got %d", len(tickets))
+	}
+	if tickets[0].ID != "OPS-2" || tickets[0].State != model.Open || tickets[0].ProcedureID() != "patch" || !tickets[0].Bool("comply") || tickets[0].UpdatedAt == nil {
+		t.Errorf("unexpected ticket: %+v", tickets[0])
+	}
+	if tickets[1].State != model.Closed || tickets[1].ClosedAt == nil {
+		t.Errorf("unexpected ticket: %+v", tickets[1])
+	}
+	expected := `{"labels":{"name":{"eq":"comply"}},"team":{"key":{"eq":"OPS"}},"updatedAt":{"gte":"2018-04-01T00:00:00Z"}}`
+	if filters[0] != expected {
+		t.Errorf("unexpected filter: %s", filters[0])
+	}
+
+	if link := l.LinkFor(tickets[0]); link != "https://linear.app/acme/issue/OPS-2" {
+		t.Errorf("unexpected link: %s", link)
+	}
+}
+
+func TestGet(t *testing.T) {
+	l, done := newTestPlugin(t, func(r *request) string {
+		if r.Variables["id"] == "OPS-9" {
+			return `{"data": null, "errors": [{"message": "Entity not found", "extensions": {"code": "INVALID_INPUT"}}]}`
+		}
+		return `{"data": {"issue": {"identifier": "OPS-2", "title": "Apply OS patches", "state": {"type": "completed"}, "completedAt": "2018-06-03T00:00:00.000Z", "labels": {"nodes": []}}}}`
+	})
+	defer done()
+
+	ticket, err := l.Get("OPS-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.State != model.Closed || ticket.ClosedAt == nil || !ticket.ClosedAt.Equal(time.Date(2018, 6, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected ticket: %+v", ticket)
+	}
+
+	if _, err := l.Get("OPS-9"); err == nil || !strings.Contains(err.Error(), "Entity not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestBacksOff(t *testing.T) {
+	var waits []time.Duration
+	backoff.Sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { backoff.Sleep = nil }()
+
+	requests := 0
+	l, done := newTestPlugin(t, func(r *request) string {
+		requests++
+		if requests == 1 {
+			return `{"data": null, "errors": [{"message": "Rate limit exceeded", "extensions": {"code": "RATELIMITED"}}]}`
+		}
+		return `{"data": {"issues": {"nodes": [], "pageInfo": {"hasNextPage": false}}}}`
+	})
+	defer done()
+
+	if _, err := l.FindOpen(); err != nil {
+		t.Fatal(err)
+	}
+	if len(waits) != 1 || waits[0] != time.Second {
+		t.Errorf("unexpected backoff: %v", waits)
+	}
+}
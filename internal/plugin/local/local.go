@@ -0,0 +1,292 @@
+package local
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/strongdm/comply/internal/config"
+	"github.com/strongdm/comply/internal/model"
+	"github.com/strongdm/comply/internal/path"
+	"gopkg.in/yaml.v2"
+)
+
+// Register causes the local plugin to register itself
+func Register() {
+	model.Register(model.Local, &localPlugin{})
+}
+
+// localPlugin stores tickets as markdown files with a YAML header in the
+// tickets folder, named after a sequence number and their title:
+//
+//	tickets/12-apply-os-patches.md
+//
+//	---
+//	title: Apply OS patches
+//	state: open
+//	labels:
+//	- comply
+//	- comply-procedure
+//	created: 2018-05-15T00:00:00Z
+//	assignee: jdoe
+//	due: 2018-05-22
+//	---
+//	Resolve this ticket by executing the following steps: ...
+//
+// The ticket ID is the file name without extension, e.g. 12-apply-os-patches,
+// so tickets numbered alike on different branches stay distinct once merged.
+// Tickets are closed by setting the state to closed and recording when in
+// closed; modification times reflect the checkout, so closed tickets without
+// it have no closing time.
+type localPlugin struct {
+	configured bool
+	labels     *model.LabelVocabulary
+	// root is replaced in tests
+	root string
+}
+
+type header struct {
+	Title     string   `yaml:"title"`
+	State     string   `yaml:"state"`
+	Labels    []string `yaml:"labels,omitempty"`
+	Created   string   `yaml:"created,omitempty"`
+	Closed    string   `yaml:"closed,omitempty"`
+	Assignee  string   `yaml:"assignee,omitempty"`
+	Priority  string   `yaml:"priority,omitempty"`
+	Due       string   `yaml:"due,omitempty"`
+	Component string   `yaml:"component,omitempty"`
+}
+
+// Prompts are human-readable configuration element names
+func (l *localPlugin) Prompts() map[string]string {
+	return map[string]string{}
+}
+
+func (l *localPlugin) Configure(cfg map[string]interface{}) error {
+	l.configured = true
+	l.labels = model.Labels()
+	return nil
+}
+
+func (l *localPlugin) Configured() bool {
+	return l.configured
+}
+
+// Links are empty, as tickets are reviewed in the repository rather than the dashboard.
+func (l *localPlugin) Links() model.TicketLinks {
+	return model.TicketLinks{}
+}
+
+// LinkFor is the ticket file, relative to the project root.
+func (l *localPlugin) LinkFor(t *model.Ticket) string {
+	folder, err := filepath.Rel(config.ProjectRoot(), l.folder())
+	if err != nil {
+		folder = l.folder()
+	}
+	return filepath.ToSlash(filepath.Join(folder, t.ID+".md"))
+}
+
+func (l *localPlugin) Get(ID string) (*model.Ticket, error) {
+	tickets, err := l.read()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tickets {
+		if t.ID == ID {
+			return t, nil
+		}
+	}
+	return nil, errors.New("no local ticket " + ID)
+}
+
+func (l *localPlugin) FindOpen() ([]*model.Ticket, error) {
+	return l.find(func(t *model.Ticket) bool {
+		return t.State == model.Open
+	})
+}
+
+// FindByTag finds tickets labeled "name:value".
+func (l *localPlugin) FindByTag(name, value string) ([]*model.Ticket, error) {
+	return l.FindByTagName(fmt.Sprintf("%s:%s", name, value))
+}
+
+func (l *localPlugin) FindByTagName(name string) ([]*model.Ticket, error) {
+	return l.FindByTagNameSince(name, time.Time{})
+}
+
+// FindByTagNameSince compares since to the file modification time.
+func (l *localPlugin) FindByTagNameSince(name string, since time.Time) ([]*model.Ticket, error) {
+	return l.find(func(t *model.Ticket) bool {
+		return t.Bool(name) && (t.UpdatedAt == nil || !t.UpdatedAt.Before(since))
+	})
+}
+
+// Create writes the ticket to a file numbered after the highest existing one.
+func (l *localPlugin) Create(ticket *model.Ticket, labels []string) error {
+	tickets, err := l.read()
+	if err != nil {
+		return err
+	}
+	next := 1
+	for _, t := range tickets {
+		if n, err := strconv.Atoi(strings.SplitN(t.ID, "-", 2)[0]); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+
+	h := &header{
+		Title:     ticket.Name,
+		State:     string(model.Open),
+		Labels:    l.labels.Labels(labels),
+		Created:   time.Now().UTC().Format(time.RFC3339),
+		Assignee:  ticket.Assignee,
+		Priority:  ticket.Priority,
+		Component: ticket.Component,
+	}
+	if ticket.DueDate != nil {
+		h.Due = ticket.DueDate.Format(model.DateFormat)
+	}
+	content, err := yaml.Marshal(h)
+	if err != nil {
+		return err
+	}
+
+	root := l.folder()
+	if err = os.MkdirAll(root, os.FileMode(0755)); err != nil {
+		return errors.Wrap(err, "unable to create tickets folder")
+	}
+	filename := filepath.Join(root, fmt.Sprintf("%d-%s.md", next, slug(ticket.Name)))
+	content = []byte(fmt.Sprintf("---\n%s---\n%s\n", content, ticket.Body))
+	if err = ioutil.WriteFile(filename, content, os.FileMode(0644)); err != nil {
+		return errors.Wrap(err, "unable to write "+filename)
+	}
+	return nil
+}
+
+func (l *localPlugin) folder() string {
+	if l.root != "" {
+		return l.root
+	}
+	return path.TicketsRoot()
+}
+
+func (l *localPlugin) find(match func(t *model.Ticket) bool) ([]*model.Ticket, error) {
+	tickets, err := l.read()
+	if err != nil {
+		return nil, err
+	}
+	var found []*model.Ticket
+	for _, t := range tickets {
+		if match(t) {
+			found = append(found, t)
+		}
+	}
+	return found, nil
+}
+
+var filenameRE = regexp.MustCompile(`^\d+(-.*)?\.md$`)
+
+func (l *localPlugin) read() ([]*model.Ticket, error) {
+	files, err := ioutil.ReadDir(l.folder())
+	if os.IsNotExist(err) {
+		return []*model.Ticket{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load tickets")
+	}
+
+	var tickets []*model.Ticket
+	for _, f := range files {
+		if f.IsDir() || !filenameRE.MatchString(f.Name()) {
+			continue
+		}
+		t, err := readTicket(filepath.Join(l.folder(), f.Name()), f, l.labels)
+		if err != nil {
+			return nil, err
+		}
+		t.ID = strings.TrimSuffix(f.Name(), ".md")
+		tickets = append(tickets, t)
+	}
+	return tickets, nil
+}
+
+func readTicket(filename string, info os.FileInfo, v *model.LabelVocabulary) (*model.Ticket, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read "+filename)
+	}
+
+	// the body may itself contain "---", e.g. ahead of ticket metadata
+	normalized := strings.Replace(string(content), "\r\n", "\n", -1)
+	components := strings.SplitN(strings.TrimPrefix(normalized, "---\n"), "\n---\n", 2)
+	if len(components) < 2 {
+		return nil, fmt.Errorf("malformed ticket %s, must be of the form: ---\\nYAML\\n---\\nmarkdown content", filename)
+	}
+	h := &header{}
+	if err = yaml.Unmarshal([]byte(components[0]), h); err != nil {
+		return nil, errors.Wrap(err, "unable to parse "+filename)
+	}
+
+	modified := info.ModTime()
+	t := &model.Ticket{Attributes: make(map[string]interface{})}
+	t.Name = h.Title
+	t.Body = strings.TrimSuffix(components[1], "\n")
+	t.State = toState(h.State)
+	t.Assignee = h.Assignee
+	t.Priority = h.Priority
+	t.Component = h.Component
+	t.UpdatedAt = &modified
+	if t.CreatedAt, err = parseTime(h.Created); err != nil {
+		return nil, errors.Wrap(err, "invalid created time in "+filename)
+	}
+	if t.DueDate, err = parseTime(h.Due); err != nil {
+		return nil, errors.Wrap(err, "invalid due date in "+filename)
+	}
+	if t.State == model.Closed {
+		if t.ClosedAt, err = parseTime(h.Closed); err != nil {
+			return nil, errors.Wrap(err, "invalid closed time in "+filename)
+		}
+	}
+
+	for _, l := range h.Labels {
+		if canonical, ok := v.Canonical(l); ok {
+			t.SetBool(canonical)
+		}
+	}
+	return t, nil
+}
+
+// parseTime accepts RFC 3339 timestamps or dates.
+func parseTime(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t, err = time.Parse(model.DateFormat, s)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func toState(state string) model.TicketState {
+	switch strings.ToLower(state) {
+	case "closed":
+		return model.Closed
+	}
+	return model.Open
+}
+
+var nonAlphanumericRE = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slug(name string) string {
+	return strings.Trim(nonAlphanumericRE.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
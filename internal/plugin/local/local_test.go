@@ -0,0 +1,184 @@
+package local
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/strongdm/comply/internal/config"
+	"github.com/strongdm/comply/internal/model"
+)
+
+func newTestPlugin(t *testing.T) (*localPlugin, func()) {
+	dir, err := ioutil.TempDir("", "comply-tickets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.SetProjectRoot(dir)
+	l := &localPlugin{root: filepath.Join(dir, "tickets")}
+	l.Configure(map[string]interface{}{})
+	return l, func() {
+		config.SetProjectRoot("")
+		os.RemoveAll(dir)
+	}
+}
+
+func TestCreateAndFind(t *testing.T) {
+	l, done := newTestPlugin(t)
+	defer done()
+
+	tickets, err := l.FindOpen()
+	if err != nil || len(tickets) != 0 {
+		t.Fatalf("expected no tickets before the folder exists, got %v (%v)", tickets, err)
+	}
+
+	body := "Resolve this ticket by executing the following steps:\n\n\n---\nProcedure-ID: patch"
+	if err = l.Create(&model.Ticket{Name: "Apply OS patches: Q2", Body: body}, []string{"comply", "comply-procedure"}); err != nil {
+		t.Fatal(err)
+	}
+	due := time.Date(2018, 5, 22, 0, 0, 0, 0, time.UTC)
+	onboard := &model.Ticket{Name: "Onboard New User", Body: "---\nProcedure-ID: onboard", Assignee: "jdoe", Priority: "high", DueDate: &due}
+	if err = l.Create(onboard, []string{"comply"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = os.Stat(filepath.Join(l.root, "1-apply-os-patches-q2.md")); err != nil {
+		t.Errorf("expected ticket file named after ID and title: %v", err)
+	}
+
+	tickets, err = l.FindByTagName("comply-procedure")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 1 {
+		t.Fatalf("expected 1 ticket, got %d", len(tickets))
+	}
+	ticket := tickets[0]
+	if ticket.ID != "1-apply-os-patches-q2" || ticket.Name != "Apply OS patches: Q2" || ticket.Body != body || ticket.ProcedureID() != "patch" {
+		t.Errorf("ticket not preserved: %+v", ticket)
+	}
+	if ticket.State != model.Open || ticket.CreatedAt == nil || ticket.ClosedAt != nil {
+		t.Errorf("unexpected state: %+v", ticket)
+	}
+	if link := l.LinkFor(ticket); link != "tickets/1-apply-os-patches-q2.md" {
+		t.Errorf("expected a link to the ticket file, got %q", link)
+	}
+
+	ticket, err = l.Get("2-onboard-new-user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.ProcedureID() != "onboard" || ticket.Assignee != "jdoe" || ticket.Priority != "high" || ticket.DueDate == nil || !ticket.DueDate.Equal(due) {
+		t.Errorf("unexpected ticket: %+v", ticket)
+	}
+
+	future, err := l.FindByTagNameSince("comply", time.Now().Add(time.Hour))
+	if err != nil || len(future) != 0 {
+		t.Errorf("expected no tickets updated in the future, got %v (%v)", future, err)
+	}
+}
+
+func TestClosed(t *testing.T) {
+	l, done := newTestPlugin(t)
+	defer done()
+
+	os.MkdirAll(l.root, 0755)
+	ioutil.WriteFile(filepath.Join(l.root, "README.md"), []byte("Tickets"), 0644)
+	ioutil.WriteFile(filepath.Join(l.root, "3-patch.md"), []byte(`---
+title: Apply OS patches
+state: Closed
+labels: [comply, comply-procedure]
+created: 2018-05-15
+closed: 2018-05-16T10:00:00Z
+---
+Done.
+`), 0644)
+	ioutil.WriteFile(filepath.Join(l.root, "4-review.md"), []byte(`---
+title: Access review
+state: closed
+---
+Done.
+`), 0644)
+
+	open, err := l.FindOpen()
+	if err != nil || len(open) != 0 {
+		t.Errorf("expected no open tickets, got %v (%v)", open, err)
+	}
+
+	ticket, err := l.Get("3-patch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.State != model.Closed || !ticket.ClosedAt.Equal(time.Date(2018, 5, 16, 10, 0, 0, 0, time.UTC)) || !ticket.CreatedAt.Equal(time.Date(2018, 5, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected ticket: %+v", ticket)
+	}
+
+	ticket, err = l.Get("4-review")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.State != model.Closed || ticket.ClosedAt != nil {
+		t.Errorf("expected no closed time without closed: %+v", ticket)
+	}
+
+	ioutil.WriteFile(filepath.Join(l.root, "5-broken.md"), []byte("no header"), 0644)
+	if _, err = l.FindOpen(); err == nil || !strings.Contains(err.Error(), "5-broken.md") {
+		t.Errorf("expected malformed ticket error, got %v", err)
+	}
+}
+
+func TestSameNumber(t *testing.T) {
+	l, done := newTestPlugin(t)
+	defer done()
+
+	// created on two branches, then merged
+	os.MkdirAll(l.root, 0755)
+	ioutil.WriteFile(filepath.Join(l.root, "12-apply-os-patches.md"), []byte("---\ntitle: Apply OS patches\nstate: open\n---\nPatch.\n"), 0644)
+	ioutil.WriteFile(filepath.Join(l.root, "12-access-review.md"), []byte("---\r\ntitle: Access review\r\nstate: closed\r\n---\r\nReview.\r\n"), 0644)
+
+	ticket, err := l.Get("12-access-review")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.Name != "Access review" || ticket.State != model.Closed || ticket.Body != "Review." {
+		t.Errorf("unexpected ticket from CRLF checkout: %+v", ticket)
+	}
+	if ticket, err = l.Get("12-apply-os-patches"); err != nil || ticket.Name != "Apply OS patches" {
+		t.Errorf("unexpected ticket: %+v (%v)", ticket, err)
+	}
+
+	if err = l.Create(&model.Ticket{Name: "Onboard New User"}, []string{"comply"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(l.root, "13-onboard-new-user.md")); err != nil {
+		t.Errorf("expected next ticket to follow the highest number: %v", err)
+	}
+}
+
+func TestLabelVocabulary(t *testing.T) {
+	l, done := newTestPlugin(t)
+	defer done()
+	l.labels = model.NewLabelVocabulary(config.Labels{Prefix: "sec-", Procedure: "runbook"})
+
+	if err := l.Create(&model.Ticket{Name: "Apply OS patches"}, []string{model.LabelComply, model.LabelProcedure}); err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(l.root, "1-apply-os-patches.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "- sec-comply\n- sec-runbook\n") {
+		t.Errorf("expected configured labels in ticket file:\n%s", content)
+	}
+
+	tickets, err := l.FindByTagName(model.LabelProcedure)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 1 || !tickets[0].Bool(model.LabelComply) {
+		t.Errorf("expected labels to round-trip, got %v", tickets)
+	}
+}
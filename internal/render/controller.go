@@ -2,7 +2,9 @@ package render
 
 import (
 	"fmt"
+	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -16,8 +18,15 @@ type project struct {
 }
 
 type stats struct {
-	ControlsTotal     int
-	ControlsSatisfied int
+	ControlsTotal         int
+	ControlsSatisfied     int
+	ControlsPartial       int
+	ControlsPlanned       int
+	ControlsNotApplicable int
+	ControlsInherited     int
+	// ControlsCoverage is a percentage, see model.Coverage
+	ControlsCoverage int
+	Standards        []*standardStats
 
 	ProcedureTotal      int
 	ProcedureOpen       int
@@ -26,6 +35,38 @@ type stats struct {
 	AuditOpen   int
 	AuditClosed int
 	AuditTotal  int
+	Audits      []*auditStats
+
+	// EvidenceFresh counts applicable controls with evidence from the current audit period
+	EvidenceFresh int
+	EvidenceTotal int
+	// EvidenceOverdue counts freshness requirements with stale or missing evidence
+	EvidenceOverdue  int
+	EvidenceExpiring int
+}
+
+// standardStats tracks control coverage for a single standard.
+type standardStats struct {
+	Name                  string
+	ControlsTotal         int
+	ControlsSatisfied     int
+	ControlsPartial       int
+	ControlsPlanned       int
+	ControlsNotApplicable int
+	ControlsInherited     int
+	ControlsCoverage      int
+}
+
+// auditStats tracks evidence requests for a single audit.
+type auditStats struct {
+	ID      string
+	Name    string
+	Auditor string
+	Period  string
+	// Requests is the number of declared requests, Open and Closed count request tickets
+	Requests int
+	Open     int
+	Closed   int
 }
 
 type renderData struct {
@@ -35,11 +76,20 @@ type renderData struct {
 	Stats      *stats
 	Narratives []*model.Document
 	Policies   []*model.Document
-	Procedures []*model.Procedure
+	Procedures []*procedure
 	Standards  []*model.Standard
 	Tickets    []*model.Ticket
 	Controls   []*control
-	Links      *model.TicketLinks
+	// Links are those of the default ticket system
+	Links *model.TicketLinks
+	// TicketSystems lists every configured ticket system
+	TicketSystems []*ticketSystem
+	// UnknownControls are satisfies references not declared by any standard
+	UnknownControls []*unknownControl
+	// EvidencePeriod describes the current audit period, if any
+	EvidencePeriod string
+	Evidence       []*controlEvidence
+	Freshness      []*freshnessCheck
 }
 
 type control struct {
@@ -47,8 +97,77 @@ type control struct {
 	ControlKey  string
 	Name        string
 	Description string
-	Satisfied   bool
-	SatisfiedBy []string
+	// Status is the effective model.ControlStatus, or empty when unaddressed
+	Status        string
+	Justification string
+	Satisfied     bool
+	SatisfiedBy   []string
+	// Inherited is set when the control is satisfied only through mappings
+	Inherited   bool
+	InheritedBy []*inheritedSatisfaction
+}
+
+// procedure is a procedure with the tickets tracking its execution.
+type procedure struct {
+	*model.Procedure
+	Tickets []*procedureTicket
+	// NextRun is when the schedule next fires, in the procedure's timezone
+	NextRun string
+}
+
+// ticketSystem is a configured ticket system with its dashboard links.
+type ticketSystem struct {
+	Name  string
+	Links model.TicketLinks
+	// ProcedureOpen counts open procedure tickets
+	ProcedureOpen int
+}
+
+type procedureTicket struct {
+	ID   string
+	Name string
+	// State is a model.TicketState
+	State string
+	// Link is empty unless the ticket system is configured
+	Link string
+}
+
+// inheritedSatisfaction is a document satisfying a control via a mapping from another control.
+type inheritedSatisfaction struct {
+	OutputFilename string
+	Via            string
+}
+
+// controlEvidence is the evidence collected for an applicable control.
+type controlEvidence struct {
+	Standard   string
+	ControlKey string
+	Name       string
+	Fresh      bool
+	Files      []*evidenceFile
+}
+
+type evidenceFile struct {
+	Path      string
+	Date      string
+	Collector string
+}
+
+// freshnessCheck is the state of a control or procedure freshness requirement.
+type freshnessCheck struct {
+	Subject   string
+	Name      string
+	Freshness string
+	// State is a model.EvidenceState
+	State     string
+	Latest    string
+	ExpiresAt string
+}
+
+type unknownControl struct {
+	Document   string
+	Standard   string
+	ControlKey string
 }
 
 func load() (*model.Data, *renderData, error) {
@@ -67,26 +186,58 @@ func load() (*model.Data, *renderData, error) {
 	controls := make([]*control, 0)
 	for _, standard := range modelData.Standards {
 		for key, c := range standard.Controls {
-			satisfactions, ok := satisfied[key]
-			satisfied := ok && len(satisfactions) > 0
-			controls = append(controls, &control{
+			satisfiers := satisfied[model.ControlKey{Standard: standard.Name, Control: key}]
+			ctrl := &control{
 				Standard:    standard.Name,
 				ControlKey:  key,
 				Name:        c.Name,
 				Description: c.Description,
-				Satisfied:   satisfied,
-				SatisfiedBy: satisfactions,
-			})
+				Status:      string(model.EffectiveStatus(satisfiers)),
+				Satisfied:   model.EffectiveStatus(satisfiers) == model.Implemented,
+				Inherited:   len(satisfiers) > 0 && !model.DirectlySatisfied(satisfiers),
+			}
+			var justifications []string
+			for _, s := range satisfiers {
+				if s.Status == model.NotApplicable && s.Justification != "" {
+					justifications = append(justifications, s.Justification)
+				}
+			}
+			ctrl.Justification = strings.Join(justifications, " ")
+			for _, s := range satisfiers {
+				if s.Inherited() {
+					ctrl.InheritedBy = append(ctrl.InheritedBy, &inheritedSatisfaction{
+						OutputFilename: s.OutputFilename,
+						Via:            s.Via.String(),
+					})
+				} else {
+					ctrl.SatisfiedBy = append(ctrl.SatisfiedBy, s.OutputFilename)
+				}
+			}
+			controls = append(controls, ctrl)
 		}
 	}
 	sort.Slice(controls, func(i, j int) bool {
+		if controls[i].Standard != controls[j].Standard {
+			return controls[i].Standard < controls[j].Standard
+		}
 		return controls[i].ControlKey < controls[j].ControlKey
 	})
 
 	rd := &renderData{}
 	rd.Narratives = modelData.Narratives
 	rd.Policies = modelData.Policies
-	rd.Procedures = modelData.Procedures
+	procedures := make(map[string]*procedure)
+	now := time.Now()
+	for _, p := range modelData.Procedures {
+		rp := &procedure{Procedure: p}
+		if next, err := p.NextRun(now); err != nil {
+			rp.NextRun = "invalid schedule"
+		} else if !next.IsZero() {
+			rp.NextRun = next.Format("2006-01-02 15:04 MST")
+		}
+		procedures[p.ID] = rp
+		rd.Procedures = append(rd.Procedures, rp)
+	}
 	rd.Standards = modelData.Standards
 	rd.Tickets = modelData.Tickets
 	rd.Links = &model.TicketLinks{}
@@ -94,17 +245,101 @@ func load() (*model.Data, *renderData, error) {
 	rd.Name = project.OrganizationName
 	rd.Controls = controls
 
-	ts, err := config.Config().TicketSystem()
+	for _, u := range model.UnknownControls(modelData) {
+		rel, err := filepath.Rel(config.ProjectRoot(), u.Document)
+		if err != nil {
+			rel = u.Document
+		}
+		rd.UnknownControls = append(rd.UnknownControls, &unknownControl{
+			Document:   rel,
+			Standard:   u.Standard,
+			ControlKey: u.ControlKey,
+		})
+	}
+
+	audit := model.CurrentAudit(modelData.Audits, time.Now())
+	if audit != nil {
+		rd.EvidencePeriod = fmt.Sprintf("%s (%s)", audit.Period, audit.Name)
+	}
+	for _, ce := range model.EvidenceStatus(modelData, audit) {
+		e := &controlEvidence{
+			Standard:   ce.Key.Standard,
+			ControlKey: ce.Key.Control,
+			Name:       ce.Name,
+			Fresh:      ce.Fresh,
+		}
+		for _, f := range ce.Evidence {
+			e.Files = append(e.Files, &evidenceFile{
+				Path:      f.Path,
+				Date:      f.DateString(),
+				Collector: f.Collector,
+			})
+		}
+		rd.Evidence = append(rd.Evidence, e)
+	}
+
+	for _, check := range model.CheckFreshness(modelData, time.Now()) {
+		fc := &freshnessCheck{
+			Subject:   check.Subject(),
+			Name:      check.Name,
+			Freshness: string(check.Freshness),
+			State:     string(check.State),
+		}
+		if check.Latest != nil {
+			fc.Latest = check.Latest.Path
+			fc.ExpiresAt = check.ExpiresAt.Format(model.DateFormat)
+		}
+		rd.Freshness = append(rd.Freshness, fc)
+	}
+
+	defaultTS, err := model.DefaultTicketSystem()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error in ticket system configuration")
+	}
+	systems, err := config.Config().TicketSystems()
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "error in ticket system configuration")
 	}
 
-	tp := model.GetPlugin(model.TicketSystem(ts))
+	tp := model.GetPlugin(defaultTS)
 	if tp.Configured() {
 		links := tp.Links()
 		rd.Links = &links
 	}
 
+	bySystem := make(map[model.TicketSystem]*ticketSystem)
+	for _, ts := range systems {
+		tp := model.GetPlugin(model.TicketSystem(ts))
+		if !tp.Configured() {
+			continue
+		}
+		s := &ticketSystem{Name: ts, Links: tp.Links()}
+		bySystem[model.TicketSystem(ts)] = s
+		rd.TicketSystems = append(rd.TicketSystems, s)
+	}
+
+	for _, t := range modelData.Tickets {
+		// tickets cached without a source came from the default ticket system
+		source := t.Source
+		if source == "" {
+			source = defaultTS
+		}
+		s, configured := bySystem[source]
+		if configured && t.State == model.Open && t.Bool(model.LabelProcedure) {
+			s.ProcedureOpen++
+		}
+
+		p, ok := procedures[t.ProcedureID()]
+		if !ok {
+			continue
+		}
+		pt := &procedureTicket{ID: t.ID, Name: t.Name, State: string(t.State)}
+		if configured {
+			pt.Link = model.GetPlugin(source).LinkFor(t)
+		}
+		p.Tickets = append(p.Tickets, pt)
+	}
+
 	return modelData, rd, nil
 }
 
@@ -123,22 +358,80 @@ func addStats(modelData *model.Data, renderData *renderData) {
 
 	satisfied := model.ControlsSatisfied(modelData)
 
+	var total model.Coverage
 	for _, std := range renderData.Standards {
-		stats.ControlsTotal += len(std.Controls)
-		for controlKey := range std.Controls {
-			if _, ok := satisfied[controlKey]; ok {
-				stats.ControlsSatisfied++
-			}
+		c := model.StandardCoverage(std, satisfied)
+		stats.Standards = append(stats.Standards, &standardStats{
+			Name:                  std.Name,
+			ControlsTotal:         c.Total,
+			ControlsSatisfied:     c.Implemented,
+			ControlsPartial:       c.Partial,
+			ControlsPlanned:       c.Planned,
+			ControlsNotApplicable: c.NotApplicable,
+			ControlsInherited:     c.Inherited,
+			ControlsCoverage:      c.Percent(),
+		})
+		total.Total += c.Total
+		total.Implemented += c.Implemented
+		total.Partial += c.Partial
+		total.Planned += c.Planned
+		total.NotApplicable += c.NotApplicable
+		total.Inherited += c.Inherited
+	}
+	stats.ControlsTotal = total.Total
+	stats.ControlsSatisfied = total.Implemented
+	stats.ControlsPartial = total.Partial
+	stats.ControlsPlanned = total.Planned
+	stats.ControlsNotApplicable = total.NotApplicable
+	stats.ControlsInherited = total.Inherited
+	stats.ControlsCoverage = total.Percent()
+
+	for _, e := range renderData.Evidence {
+		stats.EvidenceTotal++
+		if e.Fresh {
+			stats.EvidenceFresh++
 		}
 	}
 
+	for _, fc := range renderData.Freshness {
+		switch model.EvidenceState(fc.State) {
+		case model.EvidenceOverdue, model.EvidenceMissing:
+			stats.EvidenceOverdue++
+		case model.EvidenceExpiring:
+			stats.EvidenceExpiring++
+		}
+	}
+
+	audits := make(map[string]*auditStats)
+	for _, a := range modelData.Audits {
+		as := &auditStats{
+			ID:       a.ID,
+			Name:     a.Name,
+			Auditor:  a.Auditor,
+			Period:   a.Period.String(),
+			Requests: len(a.Requests),
+		}
+		audits[a.ID] = as
+		stats.Audits = append(stats.Audits, as)
+	}
+
 	for _, t := range renderData.Tickets {
-		if t.Bool("audit") {
+		if t.Bool(model.LabelAudit) {
 			stats.AuditTotal++
+			if t.State == model.Closed {
+				stats.AuditClosed++
+			}
+			if as, ok := audits[t.AuditID()]; ok {
+				if t.State == model.Closed {
+					as.Closed++
+				} else {
+					as.Open++
+				}
+			}
 		}
 
 		if t.State == model.Open {
-			if t.Bool("comply-procedure") {
+			if t.Bool(model.LabelProcedure) {
 				stats.ProcedureOpen++
 				if t.CreatedAt != nil {
 					age := int(time.Since(*t.CreatedAt).Hours() / float64(24))
@@ -147,7 +440,7 @@ func addStats(modelData *model.Data, renderData *renderData) {
 					}
 				}
 			}
-			if t.Bool("audit") {
+			if t.Bool(model.LabelAudit) {
 				stats.AuditOpen++
 			}
 		}
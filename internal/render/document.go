@@ -44,38 +44,98 @@ func createTable(name string, header []string, rows [][]string) string {
   `, strings.Join(header, "|"), stringRows, name)
 }
 
-func getMetadata(pol *model.Document) DocumentMetadata {
+// pdfSource is a narrative, policy or procedure rendered to PDF.
+type pdfSource struct {
+	Name string
+	// Label identifies the source in messages, e.g. its acronym or procedure ID
+	Label string
+	// Details are listed ahead of the satisfied controls, e.g. a procedure schedule
+	Details        [][]string
+	Satisfies      model.Satisfaction
+	Revisions      []model.Revision
+	Owner          string
+	FullPath       string
+	OutputFilename string
+	ModifiedAt     time.Time
+	Body           string
+}
+
+func documentSource(doc *model.Document) *pdfSource {
+	return &pdfSource{
+		Name:           doc.Name,
+		Label:          doc.Acronym,
+		Satisfies:      doc.Satisfies,
+		Revisions:      doc.Revisions,
+		Owner:          doc.Owner,
+		FullPath:       doc.FullPath,
+		OutputFilename: doc.OutputFilename,
+		ModifiedAt:     doc.ModifiedAt,
+		Body:           doc.Body,
+	}
+}
+
+func procedureSource(proc *model.Procedure) *pdfSource {
+	schedule := "On demand"
+	if proc.Cron != "" {
+		schedule = fmt.Sprintf("`%s`", proc.Cron)
+	}
+	return &pdfSource{
+		Name:  proc.Name,
+		Label: proc.ID,
+		Details: [][]string{
+			{"Procedure ID", proc.ID},
+			{"Schedule (cron format)", schedule},
+		},
+		Satisfies:      proc.Satisfies,
+		Revisions:      proc.Revisions,
+		FullPath:       proc.FullPath,
+		OutputFilename: proc.OutputFilename,
+		ModifiedAt:     proc.ModifiedAt,
+		Body:           proc.Body,
+	}
+}
+
+func getMetadata(src *pdfSource) DocumentMetadata {
 	cfg := config.Config()
 	metadata := DocumentMetadata{
-		Title:         pol.Name,
+		Title:         src.Name,
 		Author:        cfg.Name,
 		IncludeHeader: true,
-		HeadContent:   pol.Name,
+		HeadContent:   src.Name,
 		FootContent:   fmt.Sprintf("%s confidential %d", cfg.Name, time.Now().Year()),
-		Date:          fmt.Sprintf("%s %d", pol.ModifiedAt.Month().String(), pol.ModifiedAt.Year()),
+		Date:          fmt.Sprintf("%s %d", src.ModifiedAt.Month().String(), src.ModifiedAt.Year()),
 	}
 	includeBefore := []string{}
 
-	if len(pol.Satisfies) > 0 {
+	if len(src.Details) > 0 {
+		detailsTable := createTable("Procedure details", []string{"Property", "Value"}, src.Details)
+		includeBefore = append(includeBefore, detailsTable)
+	}
+
+	if len(src.Satisfies) > 0 {
 		var rows []([]string)
-		for standard, keys := range pol.Satisfies {
+		for standard, controls := range src.Satisfies {
+			var keys []string
+			for _, c := range controls {
+				keys = append(keys, c.String())
+			}
 			rows = append(rows, []string{standard, strings.Join(keys, ", ")})
 		}
 		satisfiesTable := createTable("Criteria satisfaction", []string{"Standard", "Criteria Satisfied"}, rows)
 		includeBefore = append(includeBefore, satisfiesTable)
 	}
 
-	if len(pol.Revisions) > 0 {
+	if len(src.Revisions) > 0 {
 		var rows []([]string)
-		for _, rev := range pol.Revisions {
+		for _, rev := range src.Revisions {
 			rows = append(rows, []string{rev.Date, rev.Comment})
 		}
 		revisionTable := createTable("Document history", []string{"Date", "Comment"}, rows)
 		includeBefore = append(includeBefore, revisionTable)
 	}
 
-	if len(pol.Owner) > 0 {
-		documentOwner := fmt.Sprintf("Policy Owner: %s\n\n", pol.Owner)
+	if len(src.Owner) > 0 {
+		documentOwner := fmt.Sprintf("Policy Owner: %s\n\n", src.Owner)
 		includeBefore = append(includeBefore, documentOwner)
 	}
 
@@ -83,8 +143,7 @@ func getMetadata(pol *model.Document) DocumentMetadata {
 	return metadata
 }
 
-// TODO: refactor and eliminate duplication among narrative, policy renderers
-func renderToFilesystem(wg *sync.WaitGroup, semaphore chan struct{}, data *renderData, doc *model.Document, live bool) {
+func renderToFilesystem(wg *sync.WaitGroup, semaphore chan struct{}, data *renderData, doc *pdfSource, live bool) {
 	// only files that have been touched
 	if !isNewer(doc.FullPath, doc.ModifiedAt) {
 		return
@@ -92,7 +151,7 @@ func renderToFilesystem(wg *sync.WaitGroup, semaphore chan struct{}, data *rende
 	recordModified(doc.FullPath, doc.ModifiedAt)
 
 	wg.Add(1)
-	go func(p *model.Document) error {
+	go func(p *pdfSource) error {
 		defer wg.Done()
 
 		semaphore <- struct{}{} // Lock
@@ -112,13 +171,13 @@ func renderToFilesystem(wg *sync.WaitGroup, semaphore chan struct{}, data *rende
 		// save preprocessed markdown
 		err := preprocessDoc(data, p, markdownPath)
 		if err != nil {
-			fmt.Printf("Unable to preprocess %s (%s) - %v\n", p.Name, p.Acronym, err)
+			fmt.Printf("Unable to preprocess %s (%s) - %v\n", p.Name, p.Label, err)
 			return err
 		}
 
 		err = pandoc(pdfRelativePath)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Unable to generate a PDF for %s (%s) - %v\n", p.Name, p.Acronym, err)
+			fmt.Fprintf(os.Stderr, "Unable to generate a PDF for %s (%s) - %v\n", p.Name, p.Label, err)
 			return err
 		}
 
@@ -139,7 +198,7 @@ func renderToFilesystem(wg *sync.WaitGroup, semaphore chan struct{}, data *rende
 	}(doc)
 }
 
-func getGitApprovalInfo(pol *model.Document) (string, error) {
+func getGitApprovalInfo(pol *pdfSource) (string, error) {
 	cfg := config.Config()
 
 	// if no approved branch specified in config.yaml, then nothing gets added to the document
@@ -177,7 +236,7 @@ func getGitApprovalInfo(pol *model.Document) (string, error) {
 	return string(gitApprovalInfo), nil
 }
 
-func preprocessDoc(data *renderData, pol *model.Document, fullPath string) error {
+func preprocessDoc(data *renderData, pol *pdfSource, fullPath string) error {
 	var w bytes.Buffer
 	bodyTemplate, err := template.New("body").Parse(pol.Body)
 	if err != nil {
@@ -24,7 +24,7 @@ func pdf(output string, live bool, errCh chan error, wg *sync.WaitGroup) {
 			return
 		}
 		for _, policy := range policies {
-			renderToFilesystem(&pdfWG, semaphore, data, policy, live)
+			renderToFilesystem(&pdfWG, semaphore, data, documentSource(policy), live)
 		}
 
 		narratives, err := model.ReadNarratives()
@@ -34,7 +34,21 @@ func pdf(output string, live bool, errCh chan error, wg *sync.WaitGroup) {
 		}
 
 		for _, narrative := range narratives {
-			renderToFilesystem(&pdfWG, semaphore, data, narrative, live)
+			renderToFilesystem(&pdfWG, semaphore, data, documentSource(narrative), live)
+		}
+
+		procedures, err := model.ReadProcedures()
+		if err != nil {
+			errCh <- errors.Wrap(err, "unable to read procedures")
+			return
+		}
+
+		for _, procedure := range procedures {
+			// procedures without an ID are reported by lint
+			if procedure.OutputFilename == "" {
+				continue
+			}
+			renderToFilesystem(&pdfWG, semaphore, data, procedureSource(procedure), live)
 		}
 
 		pdfWG.Wait()
@@ -0,0 +1,164 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/strongdm/comply/internal/config"
+	"github.com/strongdm/comply/internal/model"
+	"gopkg.in/yaml.v2"
+)
+
+// SoA output formats
+const (
+	SoAPDF  = "pdf"
+	SoACSV  = "csv"
+	SoAXLSX = "xlsx"
+)
+
+// cellEscaper keeps multi-line values, such as YAML block scalars, within a
+// single pipe-table cell.
+var cellEscaper = strings.NewReplacer("|", "\\|", "\r\n", " ", "\n", " ")
+
+var soaHeader = []string{"Standard", "Control", "Family", "Name", "Applicable", "Justification", "Status", "Implemented By"}
+
+// SoA generates a Statement of Applicability for the named standards (or all
+// standards when none are named) in each of the requested formats.
+func SoA(output string, standards []string, formats []string) error {
+	for _, format := range formats {
+		if format != SoAPDF && format != SoACSV && format != SoAXLSX {
+			return fmt.Errorf("unknown format %q, must be one of: pdf, csv, xlsx", format)
+		}
+	}
+
+	data, err := model.ReadData()
+	if err != nil {
+		return errors.Wrap(err, "unable to load data")
+	}
+
+	entries := model.StatementOfApplicability(data, standards...)
+	if len(entries) == 0 {
+		return errors.New("no controls found for the requested standards")
+	}
+
+	var rows [][]string
+	for _, e := range entries {
+		applicable := "Yes"
+		if !e.Applicable {
+			applicable = "No"
+		}
+		rows = append(rows, []string{e.Standard, e.Key, e.Family, e.Name, applicable, e.Justification, soaStatus(e), strings.Join(e.Documents, ", ")})
+	}
+
+	cfg := config.Config()
+	relativePath := fmt.Sprintf("%s-SoA", cfg.FilePrefix)
+	if cfg.PDFFolder != "" {
+		relativePath = cfg.PDFFolder + "/" + relativePath
+	}
+	err = os.MkdirAll(filepath.Dir(filepath.Join(output, relativePath)), os.FileMode(0755))
+	if err != nil {
+		return errors.Wrap(err, "unable to create output directory")
+	}
+
+	for _, format := range formats {
+		filename := relativePath + "." + format
+		switch format {
+		case SoAPDF:
+			err = soaPDF(output, filename, rows)
+		case SoACSV:
+			err = soaCSV(filepath.Join(output, filename), rows)
+		case SoAXLSX:
+			err = writeXLSX(filepath.Join(output, filename), "Statement of Applicability", append([][]string{soaHeader}, rows...))
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Statement of Applicability -> %s\n", filename)
+	}
+	return nil
+}
+
+func soaStatus(e *model.SoAEntry) string {
+	if !e.Applicable {
+		return "Not applicable"
+	}
+	switch e.Status {
+	case model.Implemented:
+		return "Implemented"
+	case model.Partial:
+		return "Partially implemented"
+	case model.Planned:
+		return "Planned"
+	}
+	return "Not implemented"
+}
+
+func soaCSV(path string, rows [][]string) error {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Write(soaHeader)
+	w.WriteAll(rows)
+	if err := w.Error(); err != nil {
+		return errors.Wrap(err, "unable to encode CSV")
+	}
+	err := ioutil.WriteFile(path, b.Bytes(), os.FileMode(0644))
+	if err != nil {
+		return errors.Wrap(err, "unable to write "+path)
+	}
+	return nil
+}
+
+// soaPDF renders the SoA as a pandoc pipe table; the PDF omits the family
+// column to fit the page.
+func soaPDF(output, pdfRelativePath string, rows [][]string) error {
+	cfg := config.Config()
+	now := time.Now()
+	metadata := DocumentMetadata{
+		Title:         "Statement of Applicability",
+		Author:        cfg.Name,
+		IncludeHeader: true,
+		HeadContent:   "Statement of Applicability",
+		FootContent:   fmt.Sprintf("%s confidential %d", cfg.Name, now.Year()),
+		Date:          fmt.Sprintf("%s %d", now.Month().String(), now.Year()),
+	}
+	ymlData, _ := yaml.Marshal(&metadata)
+
+	var w bytes.Buffer
+	fmt.Fprintf(&w, "---\n%s\n---\n\n# Statement of Applicability\n\n", ymlData)
+	w.WriteString("Standard | Control | Name | Applicable | Justification | Status | Implemented By\n")
+	w.WriteString(":--|:--|:----|:-|:------|:--|:---\n")
+	for _, row := range rows {
+		var cells []string
+		for i, cell := range row {
+			if i == 2 {
+				continue
+			}
+			cells = append(cells, cellEscaper.Replace(strings.TrimSpace(cell)))
+		}
+		w.WriteString(strings.Join(cells, " | ") + "\n")
+	}
+
+	markdownPath := filepath.Join(".", output, pdfRelativePath+".md")
+	err := ioutil.WriteFile(markdownPath, w.Bytes(), os.FileMode(0644))
+	if err != nil {
+		return errors.Wrap(err, "unable to write preprocessed SoA to disk")
+	}
+
+	err = pandoc(pdfRelativePath)
+	if err != nil {
+		return errors.Wrap(err, "unable to generate a PDF for the Statement of Applicability")
+	}
+
+	err = os.Remove(markdownPath)
+	if err != nil {
+		return errors.Wrap(err, "unable to remove preprocessed SoA")
+	}
+	return nil
+}
@@ -17,6 +17,11 @@ func watch(errCh chan error) {
 	b.Add("./narratives/")
 	b.Add("./policies/")
 	b.Add("./procedures/")
+	b.Add("./standards/")
+	b.Add("./mappings/")
+	b.Add("./applicability/")
+	b.Add("./audits/")
+	b.Add("./evidence/")
 
 	b.Add("./.comply/")
 	b.Add("./.comply/cache")
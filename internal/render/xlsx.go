@@ -0,0 +1,94 @@
+package render
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// xlsxParts are the static parts of a single-sheet SpreadsheetML package.
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// writeXLSX writes rows of inline strings to a single-sheet workbook.
+func writeXLSX(path, sheetName string, rows [][]string) error {
+	var sheet bytes.Buffer
+	sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range rows {
+		fmt.Fprintf(&sheet, `<row r="%d">`, i+1)
+		for j, cell := range row {
+			fmt.Fprintf(&sheet, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">`, xlsxColumn(j), i+1)
+			xml.EscapeText(&sheet, []byte(cell))
+			sheet.WriteString(`</t></is></c>`)
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	var workbook bytes.Buffer
+	workbook.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="`)
+	// sheet names are limited to 31 characters
+	if len(sheetName) > 31 {
+		sheetName = sheetName[:31]
+	}
+	xml.EscapeText(&workbook, []byte(sheetName))
+	workbook.WriteString(`" sheetId="1" r:id="rId1"/></sheets></workbook>`)
+
+	var b bytes.Buffer
+	z := zip.NewWriter(&b)
+	parts := append(xlsxParts, []struct {
+		name    string
+		content string
+	}{
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/worksheets/sheet1.xml", sheet.String()},
+	}...)
+	for _, part := range parts {
+		f, err := z.Create(part.name)
+		if err != nil {
+			return errors.Wrap(err, "unable to create "+part.name)
+		}
+		if _, err := f.Write([]byte(part.content)); err != nil {
+			return errors.Wrap(err, "unable to write "+part.name)
+		}
+	}
+	if err := z.Close(); err != nil {
+		return errors.Wrap(err, "unable to write workbook")
+	}
+
+	err := ioutil.WriteFile(path, b.Bytes(), os.FileMode(0644))
+	if err != nil {
+		return errors.Wrap(err, "unable to write "+path)
+	}
+	return nil
+}
+
+// xlsxColumn converts a zero-based column index to its letter reference.
+func xlsxColumn(i int) string {
+	column := ""
+	for i >= 0 {
+		column = string(rune('A'+i%26)) + column
+		i = i/26 - 1
+	}
+	return column
+}
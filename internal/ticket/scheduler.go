@@ -2,121 +2,352 @@ package ticket
 
 import (
 	"fmt"
-	"sort"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/robfig/cron"
-	"github.com/strongdm/comply/internal/config"
 	"github.com/strongdm/comply/internal/model"
 )
 
-func byProcedureByTime(tickets []*model.Ticket) map[string][]*model.Ticket {
-	result := make(map[string][]*model.Ticket)
-	for _, t := range tickets {
-		procedureID := t.ProcedureID()
-		if procedureID == "" {
-			// missing procedure metadata; skip
-			continue
+// lookback bounds how far back a missed occurrence is triggered.
+const lookback = time.Hour * 24 * (365 + 30)
+
+// missedAfter is how long after coming due an occurrence is skipped by
+// procedures which do not catch up.
+const missedAfter = 24 * time.Hour
+
+// maxCatchUp bounds the tickets created for a procedure in one run; later
+// runs continue from the last occurrence ticketed.
+const maxCatchUp = 100
+
+// Plan records whether a procedure's most recent scheduled occurrence needs a
+// ticket, and why.
+type Plan struct {
+	Procedure *model.Procedure
+	// Occurrence is the most recent occurrence, zero if the schedule has not
+	// fired within lookback
+	Occurrence time.Time
+	Next       time.Time
+	// LastTicket is the most recently created ticket for the procedure
+	LastTicket *model.Ticket
+	// Trigger lists the occurrences to ticket, oldest first
+	Trigger []time.Time
+	Reason  string
+}
+
+// TriggerScheduled creates a ticket for the most recent scheduled occurrence
+// of each procedure, unless a ticket for that occurrence is already cached or
+// present in the ticket system.
+func TriggerScheduled() error {
+	plans, err := PlanScheduled(time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	for _, p := range plans {
+		for _, occurrence := range p.Trigger {
+			err = trigger(p.Procedure, occurrence)
+			if err != nil {
+				return err
+			}
 		}
-		list, ok := result[procedureID]
-		if !ok {
-			list = make([]*model.Ticket, 0)
+	}
+	return nil
+}
+
+// TriggerProcedure creates tickets for the procedure's scheduled occurrences
+// due at now, following its catch-up policy, and returns the plan it followed.
+func TriggerProcedure(procedure *model.Procedure, now time.Time) (*Plan, error) {
+	rawTickets, err := model.ReadTickets()
+	if err != nil {
+		return nil, err
+	}
+	p, err := plan(procedure, byProcedure(rawTickets)[procedure.ID], now)
+	if err != nil {
+		return nil, err
+	}
+	for _, occurrence := range p.Trigger {
+		err = trigger(procedure, occurrence)
+		if err != nil {
+			return nil, err
 		}
-		list = append(list, t)
-		sort.Slice(list, func(i, j int) bool {
-			if list[i].CreatedAt == nil || list[j].CreatedAt == nil {
-				return false
-			}
-			return list[i].CreatedAt.Before(*list[j].CreatedAt)
-		})
-		result[procedureID] = list
 	}
-	return result
+	return p, nil
 }
 
-func TriggerScheduled() error {
+// PlanScheduled decides, without creating tickets, which procedures with a
+// cron expression TriggerScheduled would trigger at now.
+func PlanScheduled(now time.Time) ([]*Plan, error) {
 	rawTickets, err := model.ReadTickets()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	tickets := byProcedureByTime(rawTickets)
+	tickets := byProcedure(rawTickets)
 	procedures, err := model.ReadProcedures()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	var plans []*Plan
 	for _, procedure := range procedures {
 		if procedure.Cron == "" {
 			continue
 		}
-
-		procedureID := procedure.ID
-		schedule, err := cron.Parse(procedure.Cron)
+		p, err := plan(procedure, tickets[procedure.ID], now)
 		if err != nil {
-			continue
+			return nil, err
 		}
-		ticketsForProc, ok := tickets[procedureID]
-		if ok {
-			// find most recent one
-			mostRecent := ticketsForProc[len(ticketsForProc)-1]
-			if mostRecent.CreatedAt == nil {
-				continue
-			}
+		plans = append(plans, p)
+	}
+	return plans, nil
+}
 
-			// would another have triggered since?
-			nextTrigger := schedule.Next(*mostRecent.CreatedAt).UTC()
-			if nextTrigger.After(time.Now().UTC()) {
-				// in the future, nothing to do
-				continue
-			}
-			err = trigger(procedure)
-			if err != nil {
-				return err
-			}
+// plan checks the cached tickets for the procedure, then the ticket system.
+func plan(procedure *model.Procedure, cached []*model.Ticket, now time.Time) (*Plan, error) {
+	p := &Plan{Procedure: procedure, LastTicket: latest(cached)}
+
+	schedule, err := cron.Parse(procedure.Cron)
+	if err != nil {
+		p.Reason = "invalid cron expression: " + err.Error()
+		return p, nil
+	}
+	loc, err := procedure.Location()
+	if err != nil {
+		p.Reason = "invalid timezone: " + err.Error()
+		return p, nil
+	}
+	now = now.In(loc)
+	p.Next = schedule.Next(now)
+	occurrence, ok := lastOccurrence(schedule, now)
+	if !ok {
+		p.Reason = fmt.Sprintf("not scheduled in the last %d days", lookback/(24*time.Hour))
+		return p, nil
+	}
+	p.Occurrence = occurrence
+	if len(due(schedule, procedure, cached, occurrence, now)) == 0 {
+		if t := covered(cached, procedure.ID, occurrence); t != nil {
+			p.Reason = "ticket " + t.ID + " covers " + occurrence.Format(time.RFC3339)
 		} else {
-			// don't go back further than 13 months
-			tooOld := time.Now().Add(-1 * time.Hour * 24 * (365 + 30))
-			// search back one day until triggers
-			triggeredAt := time.Now().Add(-24 * time.Hour).UTC()
-		SEARCH:
-			for {
-				if triggeredAt.Before(tooOld) {
-					break SEARCH
-				}
-
-				candidate := schedule.Next(triggeredAt)
-				// in the future? not far eonugh back yet.
-				if candidate.After(time.Now().UTC()) {
-					triggeredAt = triggeredAt.Add(-24 * time.Hour)
-					continue
-				}
-
-				// is in the past? then trigger.
-				err = trigger(procedure)
-				if err != nil {
-					return err
-				}
-				break SEARCH
+			p.Reason = "missed " + occurrence.Format(time.RFC3339) + ", catchUp is none"
+		}
+		return p, nil
+	}
+
+	// tickets created since the last sync, e.g. by an earlier run
+	ts, err := model.ProcedureTicketSystem(procedure)
+	if err != nil {
+		return nil, errors.Wrap(err, "error in ticket system configuration")
+	}
+	recent, err := model.GetPlugin(ts).FindByTagName(model.TrailerLabel("Procedure-ID", procedure.ID))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to query tickets for procedure "+procedure.ID)
+	}
+	p.LastTicket = latest(append(recent, p.LastTicket))
+	p.Trigger = due(schedule, procedure, append(recent, cached...), occurrence, now)
+	switch len(p.Trigger) {
+	case 0:
+		p.Reason = "tickets cover " + occurrence.Format(time.RFC3339) + " but are not yet synced"
+	case 1:
+		p.Reason = "no ticket for " + p.Trigger[0].Format(time.RFC3339)
+	default:
+		p.Reason = fmt.Sprintf("no tickets for %d occurrences since %s", len(p.Trigger), p.Trigger[0].Format(time.RFC3339))
+	}
+	return p, nil
+}
+
+// due lists the occurrences up to the latest, occurrence, without a ticket,
+// following the procedure's catch-up policy.
+func due(schedule cron.Schedule, procedure *model.Procedure, tickets []*model.Ticket, occurrence, now time.Time) []time.Time {
+	candidates := []time.Time{occurrence}
+	switch procedure.CatchUp {
+	case model.CatchUpNone:
+		if now.Sub(occurrence) > missedAfter {
+			return nil
+		}
+	case model.CatchUpAll:
+		from := now.Add(-lookback)
+		if last := lastTicketed(schedule, tickets, procedure.ID, now.Location()); last.After(from) {
+			from = last
+		}
+		candidates = occurrences(schedule, from, now, maxCatchUp)
+	}
+
+	var result []time.Time
+	for _, c := range candidates {
+		if covered(tickets, procedure.ID, c) == nil {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// lastTicketed is the latest occurrence, in loc, with a ticket. Tickets created
+// before occurrences were recorded count from their creation; those created on
+// demand, at other times than an occurrence, are ignored.
+func lastTicketed(schedule cron.Schedule, tickets []*model.Ticket, procedureID string, loc *time.Location) time.Time {
+	var last time.Time
+	for _, t := range tickets {
+		if t.ProcedureID() != procedureID {
+			continue
+		}
+		at := t.ScheduledFor()
+		if at == nil {
+			at = t.CreatedAt
+		} else if !schedule.Next(at.In(loc).Add(-time.Second)).Equal(*at) {
+			continue
+		}
+		if at != nil && at.After(last) {
+			last = *at
+		}
+	}
+	return last
+}
+
+// Occurrences lists up to n times the cron expression fires after from and
+// no later than until, evaluated in from's location.
+func Occurrences(expr string, from, until time.Time, n int) ([]time.Time, error) {
+	schedule, err := cron.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	return occurrences(schedule, from, until, n), nil
+}
+
+func occurrences(schedule cron.Schedule, from, until time.Time, n int) []time.Time {
+	var result []time.Time
+	for next := schedule.Next(from); len(result) < n && !next.IsZero() && !next.After(until); next = schedule.Next(next) {
+		result = append(result, next)
+	}
+	return result
+}
+
+func byProcedure(tickets []*model.Ticket) map[string][]*model.Ticket {
+	result := make(map[string][]*model.Ticket)
+	for _, t := range tickets {
+		procedureID := t.ProcedureID()
+		if procedureID == "" {
+			// missing procedure metadata; skip
+			continue
+		}
+		result[procedureID] = append(result[procedureID], t)
+	}
+	return result
+}
+
+// lastOccurrence is the most recent time at or before now the schedule
+// fired, if within lookback, evaluated in now's location.
+func lastOccurrence(schedule cron.Schedule, now time.Time) (time.Time, bool) {
+	tooOld := now.Add(-lookback)
+	// search back one day until triggers
+	from := now.Add(-24 * time.Hour)
+	last := schedule.Next(from)
+	for last.IsZero() || last.After(now) {
+		// Next is zero for schedules which never fire
+		from = from.Add(-24 * time.Hour)
+		if last.IsZero() || from.Before(tooOld) {
+			return time.Time{}, false
+		}
+		last = schedule.Next(from)
+	}
+
+	// then forward to the latest occurrence
+	for next := schedule.Next(last); !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+		last = next
+	}
+	return last, true
+}
+
+// covered returns the ticket tracking the occurrence, if any. Tickets created
+// before occurrences were recorded cover any occurrence up to their creation.
+func covered(tickets []*model.Ticket, procedureID string, occurrence time.Time) *model.Ticket {
+	key := model.OccurrenceKey(procedureID, occurrence)
+	for _, t := range tickets {
+		if t.ProcedureID() != procedureID {
+			continue
+		}
+		if t.ScheduledFor() == nil {
+			if t.CreatedAt != nil && !t.CreatedAt.Before(occurrence) {
+				return t
 			}
+		} else if t.Occurrence() == key {
+			return t
+		}
+	}
+	return nil
+}
+
+// latest is the most recently created ticket, ignoring nil entries.
+func latest(tickets []*model.Ticket) *model.Ticket {
+	var result *model.Ticket
+	for _, t := range tickets {
+		if t == nil || t.CreatedAt == nil {
+			continue
 		}
+		if result == nil || t.CreatedAt.After(*result.CreatedAt) {
+			result = t
+		}
+	}
+	return result
+}
+
+// TriggerEvidenceExpiry opens a ticket for each control or procedure whose
+// evidence is expiring, overdue or missing, unless one is already open.
+func TriggerEvidenceExpiry() error {
+	checks, err := PlanEvidenceExpiry(time.Now())
+	if err != nil {
+		return err
+	}
+	if len(checks) == 0 {
+		return nil
+	}
 
+	ts, err := model.DefaultTicketSystem()
+	if err != nil {
+		return errors.Wrap(err, "error in ticket system configuration")
+	}
+	tp := model.GetPlugin(ts)
+	for _, check := range checks {
+		fmt.Printf("requesting evidence for %s (%s)\n", check.Subject(), check.State)
+		err = tp.Create(check.Ticket(), []string{model.LabelComply, model.LabelEvidence})
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func trigger(procedure *model.Procedure) error {
+// PlanEvidenceExpiry lists the freshness checks TriggerEvidenceExpiry would
+// open a ticket for at now.
+func PlanEvidenceExpiry(now time.Time) ([]*model.FreshnessCheck, error) {
+	data, err := model.ReadData()
+	if err != nil {
+		return nil, err
+	}
+
+	open := make(map[string]bool)
+	for _, t := range data.Tickets {
+		if t.Bool(model.LabelEvidence) && t.State == model.Open {
+			open[t.EvidenceFor()] = true
+		}
+	}
+
+	var checks []*model.FreshnessCheck
+	for _, check := range model.CheckFreshness(data, now) {
+		if check.State == model.EvidenceFresh || open[check.Subject()] {
+			continue
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+func trigger(procedure *model.Procedure, scheduledFor time.Time) error {
 	fmt.Printf("triggering procedure %s (cron expression: %s)\n", procedure.Name, procedure.Cron)
 
-	ts, err := config.Config().TicketSystem()
+	ts, err := model.ProcedureTicketSystem(procedure)
 	if err != nil {
 		return errors.Wrap(err, "error in ticket system configuration")
 	}
 
-	tp := model.GetPlugin(model.TicketSystem(ts))
-	err = tp.Create(&model.Ticket{
-		Name: procedure.Name,
-		Body: fmt.Sprintf("%s\n\n\n---\nProcedure-ID: %s", procedure.Body, procedure.ID),
-	}, []string{"comply", "comply-procedure"})
-	return err
+	tp := model.GetPlugin(ts)
+	return tp.Create(procedure.Ticket(scheduledFor), procedure.TicketLabels())
 }
@@ -0,0 +1,135 @@
+package ticket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robfig/cron"
+	"github.com/strongdm/comply/internal/model"
+)
+
+func TestLastOccurrence(t *testing.T) {
+	monthly, _ := cron.Parse("0 0 0 15 * *")
+	now := time.Date(2018, 6, 20, 12, 0, 0, 0, time.UTC)
+
+	occurrence, ok := lastOccurrence(monthly, now)
+	if !ok || !occurrence.Equal(time.Date(2018, 6, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected occurrence: %v", occurrence)
+	}
+
+	hourly, _ := cron.Parse("0 0 * * * *")
+	occurrence, ok = lastOccurrence(hourly, now)
+	if !ok || !occurrence.Equal(now) {
+		t.Errorf("expected occurrence at now, got %v", occurrence)
+	}
+
+	never, _ := cron.Parse("0 0 0 30 2 *")
+	if _, ok = lastOccurrence(never, now); ok {
+		t.Error("expected no occurrence")
+	}
+}
+
+func TestCovered(t *testing.T) {
+	occurrence := time.Date(2018, 6, 15, 0, 0, 0, 0, time.UTC)
+	p := &model.Procedure{ID: "patch", Name: "Apply OS patches"}
+
+	scheduled := p.Ticket(occurrence)
+	manual := p.Ticket(occurrence.Add(time.Hour))
+	if covered([]*model.Ticket{manual, scheduled}, "patch", occurrence) != scheduled {
+		t.Error("expected ticket for occurrence to cover it")
+	}
+	if covered([]*model.Ticket{manual}, "patch", occurrence) != nil {
+		t.Error("expected ticket created on demand not to cover occurrence")
+	}
+	if covered([]*model.Ticket{scheduled}, "patch", occurrence.AddDate(0, 1, 0)) != nil {
+		t.Error("expected ticket not to cover a later occurrence")
+	}
+
+	created := occurrence.Add(time.Minute)
+	legacy := &model.Ticket{Body: "---\nProcedure-ID: patch", CreatedAt: &created}
+	if covered([]*model.Ticket{legacy}, "patch", occurrence) != legacy || covered([]*model.Ticket{legacy}, "patch", occurrence.AddDate(0, 1, 0)) != nil {
+		t.Error("expected legacy ticket to cover occurrences up to its creation")
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	from := time.Date(2018, 6, 20, 12, 0, 0, 0, time.UTC)
+
+	occurrences, err := Occurrences("0 0 0 15 * *", from, from.AddDate(0, 3, 0), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(occurrences) != 3 || !occurrences[0].Equal(time.Date(2018, 7, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected occurrences within the window, got %v", occurrences)
+	}
+
+	occurrences, _ = Occurrences("0 0 * * * *", from, from.AddDate(0, 3, 0), 2)
+	if len(occurrences) != 2 {
+		t.Errorf("expected count to limit occurrences, got %v", occurrences)
+	}
+
+	occurrences, _ = Occurrences("0 0 0 30 2 *", from, from.AddDate(1, 0, 0), 5)
+	if len(occurrences) != 0 {
+		t.Errorf("expected no occurrences, got %v", occurrences)
+	}
+
+	if _, err = Occurrences("every day", from, from, 5); err == nil {
+		t.Error("expected invalid cron expression error")
+	}
+}
+
+func TestDue(t *testing.T) {
+	quarterly, _ := cron.Parse("0 0 0 1 1,4,7,10 *")
+	now := time.Date(2018, 10, 5, 0, 0, 0, 0, time.UTC)
+	occurrence := time.Date(2018, 10, 1, 0, 0, 0, 0, time.UTC)
+	p := &model.Procedure{ID: "review", Name: "Access review"}
+	tickets := []*model.Ticket{
+		p.Ticket(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)),
+		// created on demand, not for an occurrence
+		p.Ticket(time.Date(2018, 5, 3, 0, 0, 0, 0, time.UTC)),
+	}
+
+	if occurrences := due(quarterly, p, tickets, occurrence, now); len(occurrences) != 1 || !occurrences[0].Equal(occurrence) {
+		t.Errorf("expected latest occurrence by default, got %v", occurrences)
+	}
+
+	p.CatchUp = model.CatchUpAll
+	occurrences := due(quarterly, p, tickets, occurrence, now)
+	if len(occurrences) != 3 || !occurrences[0].Equal(time.Date(2018, 4, 1, 0, 0, 0, 0, time.UTC)) || !occurrences[2].Equal(occurrence) {
+		t.Errorf("expected each quarter since the last ticketed, got %v", occurrences)
+	}
+	if occurrences = due(quarterly, p, append(tickets, p.Ticket(occurrence)), occurrence, now); len(occurrences) != 0 {
+		t.Errorf("expected no occurrences once the latest is ticketed, got %v", occurrences)
+	}
+
+	p.CatchUp = model.CatchUpNone
+	if occurrences = due(quarterly, p, tickets, occurrence, now); len(occurrences) != 0 {
+		t.Errorf("expected missed occurrence to be skipped, got %v", occurrences)
+	}
+	if occurrences = due(quarterly, p, tickets, occurrence, occurrence.Add(time.Hour)); len(occurrences) != 1 {
+		t.Errorf("expected occurrence just due, got %v", occurrences)
+	}
+}
+
+func TestTimezone(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone database unavailable")
+	}
+	weekly, _ := cron.Parse("0 0 9 * * MON")
+	now := time.Date(2018, 6, 20, 12, 0, 0, 0, time.UTC).In(ny)
+
+	occurrence, ok := lastOccurrence(weekly, now)
+	if !ok || !occurrence.Equal(time.Date(2018, 6, 18, 13, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected 9am New York time, got %v", occurrence)
+	}
+
+	p := &model.Procedure{ID: "standup", Name: "Standup"}
+	tickets := []*model.Ticket{p.Ticket(occurrence)}
+	if last := lastTicketed(weekly, tickets, "standup", ny); !last.Equal(occurrence) {
+		t.Errorf("expected ticket recorded in UTC to match the occurrence, got %v", last)
+	}
+	if last := lastTicketed(weekly, tickets, "standup", time.UTC); !last.IsZero() {
+		t.Errorf("expected no occurrence at 1pm UTC, got %v", last)
+	}
+}